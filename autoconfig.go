@@ -1,6 +1,7 @@
 package wired
 
 import (
+	"fmt"
 	"os"
 	"reflect"
 	"strings"
@@ -18,6 +19,8 @@ type AutoConfig struct {
 type autoconfig struct {
 }
 
+var autoConfigType = reflect.TypeOf((*AutoConfig)(nil)).Elem()
+
 // Configurator defines a method to lookup a configuration value
 //
 type Configurator interface {
@@ -35,12 +38,25 @@ func newConfigByEnvironment() Configurator {
 	return &configByEnvironment{}
 }
 
+// configDefaults holds default configuration values of a module
+//
+type configDefaults struct {
+	values map[string]string
+}
+
+func (configDefaults *configDefaults) ConfigValue(key string) string {
+	return configDefaults.values[key]
+}
+
 type allConfigs struct {
-	all []Configurator
+	all    []Configurator
+	tracer Tracer // nil when not traced
 }
 
-func newAllConfigs(all []Configurator) *allConfigs {
-	return &allConfigs{all: all}
+// newAllConfigs consults default values of modules only after all other configurators
+//
+func newAllConfigs(all []Configurator, wire *scope) *allConfigs {
+	return &allConfigs{all: append(all, wire.configDefaults()...)}
 }
 
 // allConfigs implements wtemplate.Context
@@ -48,26 +64,71 @@ func newAllConfigs(all []Configurator) *allConfigs {
 func (allConfigs *allConfigs) Solve(key string) string {
 	for _, config := range allConfigs.all {
 		if value := config.ConfigValue(key); value != "" {
+			allConfigs.traceLookup(key, reflect.TypeOf(config))
 			return value
 		}
 	}
+	allConfigs.traceLookup(key, nil)
 	return ""
 }
 
+func (allConfigs *allConfigs) traceLookup(key string, configurator reflect.Type) {
+	if allConfigs.tracer != nil {
+		allConfigs.tracer.Trace(Event{Kind: ConfigLookedUpEvent, Key: key, Configurator: configurator})
+	}
+}
+
+// EnvironmentModule registers a configurator that looks up configuration keys
+// as environment variables. A key like server.port is looked up as SERVER_PORT
+//
+var EnvironmentModule = &Module{
+	Name:          "environment",
+	Contributions: []interface{}{newConfigByEnvironment}}
+
+// solveConfig solves a configuration template using all configurators known by a scope
+//
+func solveConfig(wire Scope, template string) (string, error) {
+	config, err := wire.TryConstruct(newAllConfigs)
+	if err != nil {
+		return "", err
+	}
+
+	configs := config.(*allConfigs)
+	configs.tracer = tracerOf(wire)
+
+	return wtemplate.Parse(configs, template), nil
+}
+
 func init() {
-	Global().Register(newConfigByEnvironment)
-	RegisterStructDecorationTag(reflect.TypeOf((*AutoConfig)(nil)).Elem(), &autoconfig{})
+	RegisterStructDecorationTag(autoConfigType, &autoconfig{})
 }
 
 func (autoconfig *autoconfig) GetValueFor(wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool) {
+	return tryGetValueFor(autoconfig, wire, obj, field, fieldType)
+}
+
+func (autoconfig *autoconfig) TryGetValueFor(wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool, error) {
 
 	tag := fieldType.Tag.Get("autoconfig")
 	if tag != "" {
-		config := wire.Construct(newAllConfigs).(*allConfigs)
-		if value := internal.ConvertString2Value(fieldType.Type.Kind(), wtemplate.Parse(config, tag)); value != internal.NilValue {
-			return value, true
+		solved, err := solveConfig(wire, tag)
+		if err != nil {
+			return internal.NilValue, false, err
+		}
+
+		if value := internal.ConvertString2Value(fieldType.Type.Kind(), solved); value != internal.NilValue {
+			return value, true, nil
+		}
+
+		if tracer := tracerOf(wire); tracer != nil {
+			tracer.Trace(Event{
+				Kind:   FieldSkippedEvent,
+				Type:   obj.Type(),
+				Field:  fieldType.Name,
+				Tag:    autoConfigType,
+				Reason: fmt.Sprintf("%q has no valid %v value", tag, fieldType.Type)})
 		}
 	}
 
-	return internal.NilValue, false
+	return internal.NilValue, false, nil
 }
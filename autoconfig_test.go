@@ -56,8 +56,9 @@ func newTestConfig() wired.Configurator {
 
 func TestSimpleStringConfig(t *testing.T) {
 	os.Setenv("FROM_ENV", "yep-from-env")
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
 
+		scope.Install(wired.EnvironmentModule)
 		scope.Register(newTestConfig)
 
 		need := scope.Construct(NewNeedConfig).(*needConfig)
@@ -1,12 +1,24 @@
 package wired
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 
 	"github.com/okke/wired/internal"
 )
 
-// AutoWire is a tag that drives autowiring of struct fields
+// AutoWire is a tag that drives autowiring of struct fields. Fields are configured
+// by an autowire field tag holding an optional name of a named constructor,
+// followed by options:
+//
+//	Replica *sql.DB `autowire:"replica"`
+//	Primary *sql.DB `autowire:",required"`
+//	Cache   *Cache  `autowire:"optional"`
+//	Clock   Clock   `autowire:"-"`
+//
+// A required field that can not be wired is an error, optional fields are left
+// alone when they can not be wired and fields tagged with "-" are never wired
 //
 type AutoWire struct {
 }
@@ -14,27 +26,204 @@ type AutoWire struct {
 type autowire struct {
 }
 
+var autoWireType = reflect.TypeOf((*AutoWire)(nil)).Elem()
+
+var errConstructedNil = errors.New("constructor returned nil")
+
 func init() {
-	RegisterStructDecorationTag(reflect.TypeOf((*AutoWire)(nil)).Elem(), &autowire{})
+	RegisterStructDecorationTag(autoWireType, &autowire{})
+}
+
+// fieldTag holds the settings of an autowire field tag
+//
+type fieldTag struct {
+	name     string
+	required bool
+	optional bool
+	skip     bool
+}
+
+func parseFieldTag(fieldType reflect.StructField) fieldTag {
+	tag := fieldTag{}
+
+	value := fieldType.Tag.Get("autowire")
+	if value == "-" {
+		tag.skip = true
+		return tag
+	}
+
+	for more := true; more; {
+		var part string
+		part, value, more = strings.Cut(value, ",")
+
+		switch part {
+		case "required":
+			tag.required = true
+		case "optional":
+			tag.optional = true
+		default:
+			if tag.name == "" {
+				tag.name = part
+			}
+		}
+	}
+	return tag
+}
+
+// resolvingScope is implemented by scopes that can tell whether they are able
+// to construct a type without constructing it
+//
+type resolvingScope interface {
+	canResolve(objType reflect.Type, name string) bool
+}
+
+// strictScope is implemented by scopes that can require all auto-wired fields to be set
+//
+type strictScope interface {
+	strictAutoWire() bool
+}
+
+// StrictAutoWire makes a scope, and all scopes created by it, fail when an exported
+// auto-wired field can not be wired, unless the field is tagged as optional
+//
+func (scope *scope) StrictAutoWire() {
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	scope.strict = true
+	scope.forgetLookups()
+}
+
+func (scope *scope) strictAutoWire() bool {
+	return scope.settings().strict
+}
+
+// requires tells whether a field must be wired
+//
+func (autowire *autowire) requires(wire Scope, structType reflect.Type, fieldType reflect.StructField, tag fieldTag) bool {
+	if tag.required {
+		return true
+	}
+	if tag.optional || !fieldType.IsExported() {
+		return false
+	}
+
+	if strict, ok := wire.(strictScope); !ok || !strict.strictAutoWire() {
+		return false
+	}
+
+	_, _, wired := autowire.fieldDependency(structType, fieldType)
+	return wired
 }
 
 // GetValueFor implements the StructDecorationTag interface
 //
 func (autowire *autowire) GetValueFor(wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool) {
+	return tryGetValueFor(autowire, wire, obj, field, fieldType)
+}
+
+// TryGetValueFor implements the ErrorStructDecorationTag interface
+//
+func (autowire *autowire) TryGetValueFor(wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool, error) {
+
+	skip := func(reason func() string) (reflect.Value, bool, error) {
+		if tracer := tracerOf(wire); tracer != nil {
+			tracer.Trace(Event{Kind: FieldSkippedEvent, Type: obj.Type(), Field: fieldType.Name, Tag: autoWireType, Reason: reason()})
+		}
+		return internal.NilValue, false, nil
+	}
+
+	tag := parseFieldTag(fieldType)
+	if tag.skip {
+		return skip(func() string { return "field is tagged to be skipped" })
+	}
 
 	// when a field has a value, auto-wiring is not applicable
 	//
 	if originalValue := internal.GetFieldValueByReflection(obj, field, fieldType); originalValue != nil {
-		return internal.NilValue, false
+		return skip(func() string { return "field already has a value" })
 	}
 
-	// when wired does not know how to construct a type, let go
+	required := autowire.requires(wire, obj.Type(), fieldType, tag)
+
+	// skip unknown types without constructing them, unless the field is required
+	// and an error is needed
 	//
-	if value := wire.ConstructByType(fieldType.Type); value == nil {
-		return reflect.ValueOf(nil), false
+	if resolving, ok := wire.(resolvingScope); ok && !required && !resolving.canResolve(fieldType.Type, tag.name) {
+		return skip(func() string { return (&ResolutionError{Type: fieldType.Type, Name: tag.name}).Error() })
+	}
+
+	value, err := wire.TryConstructNamed(fieldType.Type, tag.name)
+	if err != nil {
+
+		// when wired does not know how to construct a type, let go
+		// unless the field is required
+		//
+		if !isUnknownType(err, fieldType.Type, tag.name) {
+			return internal.NilValue, false, err
+		}
+
+		if required {
+			return internal.NilValue, false, &FieldError{Struct: obj.Type(), Field: fieldType.Name, Err: err}
+		}
+
+		return skip(err.Error)
+	}
+
+	if value == nil {
+		if required {
+			return internal.NilValue, false, &FieldError{
+				Struct: obj.Type(),
+				Field:  fieldType.Name,
+				Err:    &ResolutionError{Type: fieldType.Type, Name: tag.name, Path: []reflect.Type{fieldType.Type}, Err: errConstructedNil}}
+		}
+
+		return skip(errConstructedNil.Error)
 	}
 
 	// return value that can be auto wired
 	//
-	return reflect.ValueOf(wire.ConstructByType(fieldType.Type)), true
+	return reflect.ValueOf(value), true, nil
+}
+
+// validateField implements the fieldValidator interface. Only fields that are pointers,
+// interfaces or providers are expected to be wired, unless they are optional
+//
+func (autowire *autowire) validateField(scope *scope, structType reflect.Type, fieldType reflect.StructField) error {
+
+	tag := parseFieldTag(fieldType)
+	if tag.optional {
+		return nil
+	}
+
+	_, _, wired := autowire.fieldDependency(structType, fieldType)
+	if !(wired || tag.required) || scope.canResolve(fieldType.Type, tag.name) {
+		return nil
+	}
+
+	return &FieldError{
+		Struct: structType,
+		Field:  fieldType.Name,
+		Err:    &ResolutionError{Type: fieldType.Type, Name: tag.name, Path: []reflect.Type{fieldType.Type}}}
+}
+
+// fieldDependency implements the fieldResolver interface
+//
+func (autowire *autowire) fieldDependency(structType reflect.Type, fieldType reflect.StructField) (reflect.Type, string, bool) {
+
+	tag := parseFieldTag(fieldType)
+	if tag.skip {
+		return nil, "", false
+	}
+
+	_, provides := providedType(fieldType.Type)
+	if kind := fieldType.Type.Kind(); kind != reflect.Ptr && kind != reflect.Interface && !provides {
+		return nil, "", false
+	}
+
+	if !internal.CanSetField(structType, fieldType) {
+		return nil, "", false
+	}
+
+	return fieldType.Type, tag.name, true
 }
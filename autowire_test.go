@@ -1,7 +1,9 @@
 package wired_test
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/okke/wired"
@@ -134,3 +136,75 @@ func TestNewFieldDecorator(t *testing.T) {
 		}
 	})
 }
+
+type requiresUnknown struct {
+	wired.AutoWire
+
+	Unknown *unknownStruct `autowire:"required"`
+}
+
+type strictlyWired struct {
+	wired.AutoWire
+
+	Empty    *emptyStruct
+	Unknown  *unknownStruct
+	Optional *unknownStruct `autowire:"optional"`
+	Skipped  *emptyStruct   `autowire:"-"`
+}
+
+type lenientlyWired struct {
+	wired.AutoWire
+
+	Empty    *emptyStruct
+	Optional *unknownStruct `autowire:"optional"`
+	Skipped  *emptyStruct   `autowire:"-"`
+}
+
+func expectFieldError(t *testing.T, err error, structType reflect.Type, field string) {
+	t.Helper()
+
+	var fieldErr *wired.FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatal("expected a field error, not", err)
+	}
+	if fieldErr.Struct != structType || fieldErr.Field != field {
+		t.Error("expected field", field, "of", structType, "to be reported, not", fieldErr.Field, "of", fieldErr.Struct)
+	}
+
+	var resolutionErr *wired.ResolutionError
+	if !errors.As(err, &resolutionErr) || resolutionErr.Type != reflect.TypeOf((*unknownStruct)(nil)) {
+		t.Error("expected missing type to be reported, not", err)
+	}
+}
+
+func TestRequiredFieldShouldFail(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *requiresUnknown { return &requiresUnknown{} })
+
+		err := scope.TryInject(func(requires *requiresUnknown) {})
+		expectFieldError(t, err, reflect.TypeOf(requiresUnknown{}), "Unknown")
+	})
+}
+
+func TestStrictAutoWire(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.StrictAutoWire()
+		scope.Register(newEmptyStruct)
+		scope.Register(func() *strictlyWired { return &strictlyWired{} })
+		scope.Register(func() *lenientlyWired { return &lenientlyWired{} })
+
+		scope.Go(func(inner wired.Scope) {
+			err := inner.TryInject(func(strict *strictlyWired) {})
+			expectFieldError(t, err, reflect.TypeOf(strictlyWired{}), "Unknown")
+
+			inner.Inject(func(lenient *lenientlyWired) {
+				if lenient.Empty == nil {
+					t.Error("expected empty struct to be wired")
+				}
+				if lenient.Skipped != nil {
+					t.Error("expected skipped field not to be wired")
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,143 @@
+package wired
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// alias binds an interface to the constructor of a type that implements it
+//
+type alias struct {
+	objType reflect.Type // interface type
+	target  reflect.Type // type that implements the interface
+	name    string       // name the target constructor was registered with
+}
+
+func (alias *alias) resolve(activeScope *scope, res *resolution) (interface{}, error) {
+	return activeScope.constructByType(res, alias.target, alias.name, dependency{implements: alias.objType})
+}
+
+// RegisterAs registers a constructor function for the type it constructs and
+// for all given interfaces. When the constructed type is a singleton, all
+// interfaces share the same instance
+//
+func (scope *scope) RegisterAs(constructor interface{}, ifaceTypes ...reflect.Type) {
+
+	constructorType, constructs := constructedType(ensureConstructorIsAFunction(constructor))
+	if !constructs {
+		panic("constructor does not construct anything")
+	}
+
+	for _, ifaceType := range ifaceTypes {
+		if ifaceType.Kind() != reflect.Interface {
+			panic(fmt.Sprintf("%v is not an interface", ifaceType))
+		}
+		if !constructorType.Implements(ifaceType) {
+			panic(fmt.Sprintf("%v does not implement %v", constructorType, ifaceType))
+		}
+	}
+
+	scope.Register(constructor)
+
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	for _, ifaceType := range ifaceTypes {
+		bound := &alias{objType: ifaceType, target: constructorType}
+
+		entry := &element{constructor: bound, function: functionOf(constructor)}
+		scope.registerSliceConstructor(entry, ifaceType)
+		scope.registerKeyedMapConstructor(entry, ifaceType, constructorType)
+
+		scope.constructorMapping[ifaceType] = bound
+	}
+	scope.forgetLookups()
+}
+
+// BindImplementations makes a scope, and all scopes created by it, bind a requested
+// interface that has no constructor to the registered constructor of a type
+// implementing it. Resolution fails when multiple types implement the interface
+//
+func (scope *scope) BindImplementations() {
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	scope.bindImplementations = true
+	scope.forgetLookups()
+}
+
+func (scope *scope) bindsImplementations() bool {
+	return scope.settings().bindImplementations
+}
+
+// findImplementation looks for a single registered constructor of a type that
+// implements given interface. It returns nil when no implementation is found
+//
+func (scope *scope) findImplementation(ifaceType reflect.Type, name string) (*alias, error) {
+
+	if ifaceType.Kind() != reflect.Interface || !scope.bindsImplementations() {
+		return nil, nil
+	}
+
+	implementations := make(map[reflect.Type]bool)
+	for walk := scope; walk != nil; walk = walk.parent {
+		for _, objType := range walk.implementationsOf(ifaceType, name) {
+			implementations[objType] = true
+		}
+	}
+
+	if len(implementations) == 0 {
+		return nil, nil
+	}
+
+	if len(implementations) > 1 {
+		names := make([]string, 0, len(implementations))
+		for objType := range implementations {
+			names = append(names, fmt.Sprint(objType))
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("ambiguous binding, implemented by %s", strings.Join(names, " and "))
+	}
+
+	for objType := range implementations {
+		return &alias{objType: ifaceType, target: objType, name: name}, nil
+	}
+	return nil, nil
+}
+
+// implementationsOf returns all types of a single scope that implement given interface
+// and have a constructor registered with given name
+//
+func (scope *scope) implementationsOf(ifaceType reflect.Type, name string) []reflect.Type {
+	scope.lock.RLock()
+	defer scope.lock.RUnlock()
+
+	implementations := make([]reflect.Type, 0)
+	implements := func(objType reflect.Type, constructor interface{}) {
+		if _, ok := constructor.(*aggregator); ok {
+			return
+		}
+		if _, ok := constructor.(*alias); ok {
+			return
+		}
+		if objType != ifaceType && objType.Implements(ifaceType) {
+			implementations = append(implementations, objType)
+		}
+	}
+
+	if name == "" {
+		for objType, constructor := range scope.constructorMapping {
+			implements(objType, constructor)
+		}
+	} else {
+		for key, constructor := range scope.namedMapping {
+			if key.name == name {
+				implements(key.objType, constructor)
+			}
+		}
+	}
+
+	return implementations
+}
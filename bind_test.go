@@ -0,0 +1,116 @@
+package wired_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/okke/wired"
+	"github.com/okke/wired/internal"
+)
+
+type reader interface {
+	Read() string
+}
+
+type writer interface {
+	Write(value string)
+}
+
+type fileStore struct {
+	wired.Singleton
+
+	value string
+}
+
+func (fileStore *fileStore) Read() string {
+	return fileStore.value
+}
+
+func (fileStore *fileStore) Write(value string) {
+	fileStore.value = value
+}
+
+func newFileStore() *fileStore {
+	return &fileStore{}
+}
+
+type memoryStore struct {
+}
+
+func (memoryStore *memoryStore) Read() string {
+	return "memory"
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{}
+}
+
+var readerType = reflect.TypeOf((*reader)(nil)).Elem()
+var writerType = reflect.TypeOf((*writer)(nil)).Elem()
+
+func TestRegisterAs(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterAs(newFileStore, readerType, writerType)
+
+		scope.Inject(func(r reader, w writer, store *fileStore) {
+			w.Write("chipotle")
+			if r.Read() != "chipotle" {
+				t.Error("expected reader and writer to share a singleton, not", r.Read())
+			}
+			if r != store {
+				t.Error("expected interfaces to be bound to the concrete singleton")
+			}
+		})
+
+		scope.Inject(func(readers []reader) {
+			if len(readers) != 1 {
+				t.Error("expected bound interface in slice, not", len(readers))
+			}
+		})
+	})
+}
+
+func TestRegisterAsShouldPanicOnUnimplementedInterface(t *testing.T) {
+	defer internal.ShouldPanic(t)()
+
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterAs(newMemoryStore, writerType)
+	})
+}
+
+func TestBindImplementations(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newFileStore)
+
+		if _, err := scope.TryConstructByType(readerType); err == nil {
+			t.Error("expected interfaces not to be bound by default")
+		}
+
+		scope.BindImplementations()
+
+		scope.Go(func(inner wired.Scope) {
+			inner.Inject(func(r reader, w writer) {
+				if r.(*fileStore) != w.(*fileStore) {
+					t.Error("expected interfaces to be bound to the same singleton")
+				}
+			})
+
+			if err := inner.Validate(); err != nil {
+				t.Error("expected bound interfaces to validate, not", err)
+			}
+
+			inner.Register(newMemoryStore)
+
+			_, err := inner.TryConstructByType(readerType)
+			var resolutionErr *wired.ResolutionError
+			if !errors.As(err, &resolutionErr) || resolutionErr.Err == nil {
+				t.Fatal("expected ambiguous binding to fail, not", err)
+			}
+			if !strings.Contains(err.Error(), "ambiguous") {
+				t.Error("expected an ambiguous binding, not", err)
+			}
+		})
+	})
+}
@@ -98,8 +98,10 @@ func newArgumentSupport() *argumentSupport {
 	return &argumentSupport{}
 }
 
-// register the argument parser at global scope
+// Module registers argument support, which provides Arguments parsed from the
+// command line, and a configurator that looks up configuration keys as flags
 //
-func init() {
-	wired.Global().Register(newArgumentSupport)
-}
+var Module = &wired.Module{
+	Name:          "cli",
+	Constructors:  []interface{}{newArgumentSupport},
+	Contributions: []interface{}{newConfigByFlags}}
@@ -9,7 +9,8 @@ import (
 
 func TestParserShouldAcceptNonflags(t *testing.T) {
 
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Inject(func(parser cli.ArgumentParser) {
 			arguments := parser.Parse([]string{"uno", "dos"})
 
@@ -31,7 +32,8 @@ func TestParserShouldAcceptNonflags(t *testing.T) {
 
 func TestParserShouldAcceptFlags(t *testing.T) {
 
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Inject(func(parser cli.ArgumentParser) {
 
 			arguments := parser.Parse([]string{"-first", "uno", "--second", "dos"})
@@ -61,7 +63,8 @@ func TestParserShouldAcceptFlags(t *testing.T) {
 
 func TestParserShouldAcceptAMixOfFlagsAndNonFlags(t *testing.T) {
 
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Inject(func(parser cli.ArgumentParser) {
 
 			arguments := parser.Parse([]string{"command", "-first", "uno", "--second", "dos", "chipotle"})
@@ -99,7 +102,8 @@ func TestParserShouldAcceptAMixOfFlagsAndNonFlags(t *testing.T) {
 
 func TestArgumentProviderShouldExist(t *testing.T) {
 
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Inject(func(provider cli.ArgumentProvider, parser cli.ArgumentParser) {
 			if provider == nil {
 				t.Fatal("expected an argument provider")
@@ -121,7 +125,8 @@ func newArgumentProdiverMock() cli.ArgumentProvider {
 }
 func TestArgumentProviderShouldBeOveridable(t *testing.T) {
 
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Register(newArgumentProdiverMock)
 
 		scope.Inject(func(provider cli.ArgumentProvider, parser cli.ArgumentParser) {
@@ -138,7 +143,8 @@ func TestArgumentProviderShouldBeOveridable(t *testing.T) {
 }
 
 func TestArgumentsShouldBeSimplyThere(t *testing.T) {
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Inject(func(arguments cli.Arguments) {
 			if arguments == nil {
 				t.Fatal("expected arguments")
@@ -148,7 +154,8 @@ func TestArgumentsShouldBeSimplyThere(t *testing.T) {
 }
 
 func TestArgumentsShouldBeSimplyThereAlsoWhenMocked(t *testing.T) {
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Register(newArgumentProdiverMock)
 
 		scope.Inject(func(arguments cli.Arguments) {
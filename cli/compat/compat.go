@@ -0,0 +1,22 @@
+// Package compat installs the modules of wired and its cli package in the global
+// scope, like the cli package did before modules existed. Import it for its side
+// effect only:
+//
+//	import _ "github.com/okke/wired/cli/compat"
+//
+package compat
+
+import (
+	"github.com/okke/wired"
+	"github.com/okke/wired/cli"
+
+	// core modules are installed as well
+	//
+	_ "github.com/okke/wired/compat"
+)
+
+func init() {
+	if err := wired.Global().Install(cli.Module); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,22 @@
+package compat_test
+
+import (
+	"testing"
+
+	"github.com/okke/wired"
+	"github.com/okke/wired/cli"
+
+	_ "github.com/okke/wired/cli/compat"
+)
+
+func TestGlobalScopeShouldKnowArguments(t *testing.T) {
+	wired.Global().Go(func(scope wired.Scope) {
+		if _, err := wired.Get[cli.Arguments](scope); err != nil {
+			t.Error("expected arguments to be known globally, not", err)
+		}
+
+		if configs := wired.MustGet[[]wired.Configurator](scope); len(configs) != 2 {
+			t.Error("expected configurators of environment and flags, not", configs)
+		}
+	})
+}
@@ -20,7 +20,3 @@ func (configByArguments *configByArguments) ConfigValue(key string) string {
 func newConfigByFlags() wired.Configurator {
 	return &configByArguments{}
 }
-
-func init() {
-	wired.Global().Register(newConfigByFlags)
-}
@@ -31,7 +31,8 @@ func newNeedConfig() *needConfig {
 
 func TestConfigByArguments(t *testing.T) {
 
-	wired.Global().Go(func(scope wired.Scope) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(cli.Module)
 		scope.Register(newMockProviderForConfig)
 		scope.Register(newNeedConfig)
 
@@ -0,0 +1,14 @@
+// Package compat installs the modules of wired in the global scope, like wired
+// did before modules existed. Import it for its side effect only:
+//
+//	import _ "github.com/okke/wired/compat"
+//
+package compat
+
+import "github.com/okke/wired"
+
+func init() {
+	if err := wired.Global().Install(wired.EnvironmentModule); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,158 @@
+package wired_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+// these tests are most valuable when run with the race detector:
+//
+// go test -race ./...
+//
+
+const goRoutines = 64
+
+func concurrently(f func(nr int)) {
+	var wait sync.WaitGroup
+	wait.Add(goRoutines)
+	for walk := 0; walk < goRoutines; walk++ {
+		go func(nr int) {
+			defer wait.Done()
+			f(nr)
+		}(walk)
+	}
+	wait.Wait()
+}
+
+type contendedSingleton struct {
+	wired.Singleton
+}
+
+var contendedConstructions int32
+
+func newContendedSingleton() *contendedSingleton {
+	atomic.AddInt32(&contendedConstructions, 1)
+	return &contendedSingleton{}
+}
+
+type usesContendedSingleton struct {
+	wired.AutoWire
+
+	Singleton *contendedSingleton
+}
+
+func newUsesContendedSingleton() *usesContendedSingleton {
+	return &usesContendedSingleton{}
+}
+
+func TestSingletonShouldBeConstructedOnceUnderContention(t *testing.T) {
+	atomic.StoreInt32(&contendedConstructions, 0)
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newContendedSingleton)
+		scope.Register(newUsesContendedSingleton)
+
+		found := make([]*contendedSingleton, goRoutines)
+		concurrently(func(nr int) {
+			scope.Inject(func(uses *usesContendedSingleton) {
+				found[nr] = uses.Singleton
+			})
+		})
+
+		if constructions := atomic.LoadInt32(&contendedConstructions); constructions != 1 {
+			t.Error("expected singleton to be constructed once, not", constructions, "times")
+		}
+
+		for _, singleton := range found {
+			if singleton != found[0] {
+				t.Fatal("expected all go routines to use the same singleton")
+			}
+		}
+	})
+}
+
+type contendedListener struct {
+	nr int
+}
+
+func TestConcurrentRegisterAndInject(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+
+		concurrently(func(nr int) {
+			scope.Register(func() *contendedListener {
+				return &contendedListener{nr: nr}
+			})
+
+			scope.Inject(func(listeners []*contendedListener) {
+				if len(listeners) == 0 {
+					t.Error("expected at least one listener")
+				}
+			})
+		})
+
+		scope.Inject(func(listeners []*contendedListener) {
+			if len(listeners) != goRoutines {
+				t.Error("expected", goRoutines, "listeners, not", len(listeners))
+			}
+		})
+	})
+}
+
+func TestConcurrentSubScopes(t *testing.T) {
+	atomic.StoreInt32(&contendedConstructions, 0)
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newContendedSingleton)
+		scope.Register(newUsesContendedSingleton)
+
+		concurrently(func(nr int) {
+			scope.Go(func(inner wired.Scope) {
+				inner.Register(newEmptyStruct)
+				inner.Inject(func(uses *usesContendedSingleton, empty *emptyStruct) {
+					if uses.Singleton == nil {
+						t.Error("expected a singleton")
+					}
+				})
+			})
+		})
+
+		// all sub scopes share the singleton owned by the scope
+		// its constructor is registered in
+		//
+		if constructions := atomic.LoadInt32(&contendedConstructions); constructions != 1 {
+			t.Error("expected singleton to be constructed once, not", constructions, "times")
+		}
+	})
+}
+
+type contendedFactory struct {
+	wired.Factory
+}
+
+func (contendedFactory *contendedFactory) ConstructListener() *contendedListener {
+	return &contendedListener{}
+}
+
+func newContendedFactory() *contendedFactory {
+	return &contendedFactory{}
+}
+
+func TestFactoryMethodsShouldBeRegisteredOnce(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newContendedFactory)
+
+		concurrently(func(nr int) {
+			scope.Inject(func(factory *contendedFactory, listener *contendedListener) {
+			})
+		})
+
+		scope.Inject(func(listeners []*contendedListener) {
+			if len(listeners) != 1 {
+				t.Error("expected one listener, not", len(listeners))
+			}
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package wired
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ProfilesKey is the configuration key that holds the active profiles, separated
+// by commas. Using the environment, profiles are activated by WIRED_PROFILES=dev,test
+//
+const ProfilesKey = "wired.profiles"
+
+// Condition decides whether a constructor is registered by RegisterIf. It's
+// evaluated when the constructor is registered
+//
+type Condition func(scope Scope) bool
+
+// RegisterIf registers a constructor only when given condition holds
+//
+func (scope *scope) RegisterIf(condition Condition, constructor interface{}) {
+	if condition(scope) {
+		scope.Register(constructor)
+	}
+}
+
+// OnConfig returns a condition that holds when a configuration expression is true.
+// An expression compares two configuration templates, like "${storage.kind} == s3"
+// or "${storage.kind} != s3". An expression without comparison is true when its
+// template solves to a value other than an empty string or false. Configuration
+// values are looked up by the configurators known when the condition is evaluated
+//
+func OnConfig(expression string) Condition {
+	return func(scope Scope) bool {
+		for _, operator := range []string{"==", "!="} {
+			if left, right, found := strings.Cut(expression, operator); found {
+				return (mustSolveConfig(scope, left) == mustSolveConfig(scope, right)) == (operator == "==")
+			}
+		}
+
+		value := mustSolveConfig(scope, expression)
+		return value != "" && value != "false"
+	}
+}
+
+// OnProfile returns a condition that holds when one of given profiles is active
+//
+func OnProfile(profiles ...string) Condition {
+	return func(scope Scope) bool {
+		for _, active := range strings.Split(mustSolveConfig(scope, "${"+ProfilesKey+"}"), ",") {
+			for _, profile := range profiles {
+				if strings.TrimSpace(active) == profile {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// OnRegistered returns a condition that holds when a constructor of given type is
+// registered in the scope, or one of its parents
+//
+func OnRegistered(objType reflect.Type) Condition {
+	return func(scope Scope) bool {
+		wire, ok := scopeOf(scope)
+		if !ok {
+			return false
+		}
+		_, found := wire.findConstructor(objType, "")
+		return found
+	}
+}
+
+// Not returns a condition that holds when given condition does not
+//
+func Not(condition Condition) Condition {
+	return func(scope Scope) bool {
+		return !condition(scope)
+	}
+}
+
+func mustSolveConfig(scope Scope, template string) string {
+	solved, err := solveConfig(scope, strings.TrimSpace(template))
+	if err != nil {
+		panic(err)
+	}
+	return solved
+}
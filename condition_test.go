@@ -0,0 +1,77 @@
+package wired_test
+
+import (
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type conditionalStorage interface {
+	Kind() string
+}
+
+type diskStorage struct{}
+
+func (diskStorage *diskStorage) Kind() string {
+	return "disk"
+}
+
+type cloudStorage struct{}
+
+func (cloudStorage *cloudStorage) Kind() string {
+	return "cloud"
+}
+
+func newDiskStorage() conditionalStorage {
+	return &diskStorage{}
+}
+
+func newCloudStorage() conditionalStorage {
+	return &cloudStorage{}
+}
+
+func TestRegisterIfConfig(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newTestConfig)
+
+		scope.RegisterIf(wired.OnConfig("${pepper} == habanero"), newCloudStorage)
+		scope.RegisterIf(wired.OnConfig("${pepper} != habanero"), newDiskStorage)
+
+		if kind := wired.MustGet[conditionalStorage](scope).Kind(); kind != "cloud" {
+			t.Error("expected cloud storage, not", kind)
+		}
+
+		if !wired.OnConfig("${pepper}")(scope) || wired.OnConfig("${unknown:false}")(scope) {
+			t.Error("expected configuration values to be tested for being set")
+		}
+	})
+}
+
+func TestRegisterIfProfile(t *testing.T) {
+	t.Setenv("WIRED_PROFILES", "dev, test")
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(wired.EnvironmentModule)
+
+		scope.RegisterIf(wired.OnProfile("prod"), newCloudStorage)
+		scope.RegisterIf(wired.OnProfile("test"), newDiskStorage)
+
+		if kind := wired.MustGet[conditionalStorage](scope).Kind(); kind != "disk" {
+			t.Error("expected disk storage in test profile, not", kind)
+		}
+	})
+}
+
+func TestRegisterIfRegistered(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newCloudStorage)
+
+		scope.Go(func(sub wired.Scope) {
+			sub.RegisterIf(wired.Not(wired.OnRegistered(wired.TypeOf[conditionalStorage]())), newDiskStorage)
+
+			if kind := wired.MustGet[conditionalStorage](sub).Kind(); kind != "cloud" {
+				t.Error("expected storage of parent scope, not", kind)
+			}
+		})
+	})
+}
@@ -0,0 +1,25 @@
+package wired
+
+import (
+	"context"
+	"reflect"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// withContext returns a resolution that continues this resolution for given context
+//
+func (res *resolution) withContext(ctx context.Context) *resolution {
+	continued := *res
+	continued.ctx = ctx
+	return &continued
+}
+
+// done returns the error of the context of a resolution when this context is done
+//
+func (res *resolution) done() error {
+	if res.ctx == nil {
+		return nil
+	}
+	return res.ctx.Err()
+}
@@ -0,0 +1,110 @@
+package wired_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type contextKey string
+
+type requestHandler struct {
+	user string
+}
+
+func newRequestHandler(ctx context.Context) *requestHandler {
+	user, _ := ctx.Value(contextKey("user")).(string)
+	return &requestHandler{user: user}
+}
+
+type requestFactory struct {
+	wired.Factory
+}
+
+func (requestFactory *requestFactory) ConstructListener(ctx context.Context) *contendedListener {
+	return &contendedListener{nr: len(ctx.Value(contextKey("user")).(string))}
+}
+
+func newRequestFactory() *requestFactory {
+	return &requestFactory{}
+}
+
+func TestInjectContext(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newRequestHandler)
+		scope.Register(newRequestFactory)
+
+		ctx := context.WithValue(context.Background(), contextKey("user"), "okke")
+
+		scope.InjectContext(ctx, func(handler *requestHandler, factory *requestFactory, listener *contendedListener) {
+			if handler.user != "okke" {
+				t.Error("expected context to be handed over, not", handler.user)
+			}
+			if listener.nr != 4 {
+				t.Error("expected context to be handed over to factory methods, not", listener.nr)
+			}
+		})
+
+		scope.Inject(func(handler *requestHandler) {
+			if handler.user != "" {
+				t.Error("expected context not to be handed over without InjectContext, not", handler.user)
+			}
+		})
+	})
+}
+
+type cancelingStruct struct {
+}
+
+type afterCancelingStruct struct {
+}
+
+func TestInjectContextShouldStopWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *cancelingStruct {
+			cancel()
+			return &cancelingStruct{}
+		})
+
+		called := false
+		scope.Register(func(canceling *cancelingStruct) *afterCancelingStruct {
+			called = true
+			return &afterCancelingStruct{}
+		})
+
+		err := scope.TryInjectContext(ctx, func(after *afterCancelingStruct) {})
+		if !errors.Is(err, context.Canceled) {
+			t.Error("expected resolution to be canceled, not", err)
+		}
+
+		if called {
+			t.Error("constructor should not be called once the context is canceled")
+		}
+	})
+}
+
+func TestContextShouldBeInjectedWithoutInjectContext(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newRequestHandler)
+
+		if err := scope.Validate(); err != nil {
+			t.Error("expected context to be resolvable, not", err)
+		}
+
+		if handler, err := wired.Get[*requestHandler](scope); err != nil || handler.user != "" {
+			t.Error("expected handler to be constructed with an empty context, not", handler, err)
+		}
+
+		if err := scope.TryInject(func(ctx context.Context) {
+			if ctx == nil {
+				t.Error("expected a context")
+			}
+		}); err != nil {
+			t.Error("expected context to be injected, not", err)
+		}
+	})
+}
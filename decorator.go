@@ -0,0 +1,124 @@
+package wired
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// decoratedType returns the type a decorator decorates. A decorator accepts an
+// object of this type as its first argument and returns an object of the same
+// type, optionally followed by an error
+//
+func decoratedType(decorator interface{}) (reflect.Type, bool) {
+	decoratorType := reflect.TypeOf(decorator)
+	if decoratorType == nil || decoratorType.Kind() != reflect.Func || decoratorType.NumIn() == 0 {
+		return nil, false
+	}
+
+	outType, constructs := constructedType(decoratorType)
+	if !constructs || outType != decoratorType.In(0) {
+		return nil, false
+	}
+
+	if decoratorType.NumOut() > 2 || (decoratorType.NumOut() == 2 && !returnsError(decoratorType)) {
+		return nil, false
+	}
+	return outType, true
+}
+
+// Decorate registers a decorator that wraps every object of the type it
+// decorates. Decorators are applied in order of registration, decorators of
+// parent scopes first. Singletons owned by a parent scope are wrapped by the
+// decorators of that parent once, and by the decorators of sub scopes every
+// time they are resolved in those sub scopes
+//
+func (scope *scope) Decorate(decorator interface{}) {
+	decorated, ok := decoratedType(decorator)
+	if !ok {
+		panic(fmt.Sprintf("%v does not decorate anything, a decorator should accept and return the type it decorates", reflect.TypeOf(decorator)))
+	}
+
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	if scope.decorators == nil {
+		scope.decorators = make(map[reflect.Type][]interface{})
+	}
+	scope.decorators[decorated] = append(scope.decorators[decorated], decorator)
+	scope.forgetLookups()
+}
+
+// decoratorsOf returns all decorators of a type known by this scope and its
+// parents, in order of application
+//
+func (scope *scope) decoratorsOf(objType reflect.Type) []interface{} {
+	return scope.settings().decorators[objType]
+}
+
+// wrap applies all decorators of a type to a constructed object
+//
+func (scope *scope) wrap(res *resolution, objType reflect.Type, constructed interface{}) (interface{}, error) {
+	return scope.applyDecorators(res, objType, scope.decoratorsOf(objType), constructed)
+}
+
+// wrapOwned applies the decorators of this scope and its parents that the owner of
+// a constructed object does not know about
+//
+func (scope *scope) wrapOwned(res *resolution, owner *scope, constructor interface{}, constructed interface{}) (interface{}, error) {
+	if owner == scope {
+		return constructed, nil
+	}
+
+	plan, err := planConstructor(constructor)
+	if err != nil {
+		return nil, err
+	}
+	return scope.wrapBetween(res, owner, plan.outType, constructed)
+}
+
+// wrapBetween applies the decorators of a type known by this scope and its parents,
+// up to given owner
+//
+func (scope *scope) wrapBetween(res *resolution, owner *scope, objType reflect.Type, constructed interface{}) (interface{}, error) {
+	var decorators []interface{}
+	for walk := scope; walk != nil && walk != owner; walk = walk.parent {
+		walk.lock.RLock()
+		decorators = append(append([]interface{}{}, walk.decorators[objType]...), decorators...)
+		walk.lock.RUnlock()
+	}
+	return scope.applyDecorators(res, objType, decorators, constructed)
+}
+
+// applyDecorators applies given decorators to a constructed object
+//
+func (scope *scope) applyDecorators(res *resolution, objType reflect.Type, decorators []interface{}, constructed interface{}) (interface{}, error) {
+	for _, decorator := range decorators {
+		decoratorType := reflect.TypeOf(decorator)
+
+		in := make([]reflect.Value, decoratorType.NumIn())
+		in[0] = reflect.New(objType).Elem()
+		if constructed != nil {
+			in[0].Set(reflect.ValueOf(constructed))
+		}
+
+		for walk := 1; walk < len(in); walk++ {
+			arg, err := scope.constructByType(res, decoratorType.In(walk), "", dependency{constructor: decorator})
+			if err != nil {
+				return nil, requestedBy(err, decorator)
+			}
+
+			in[walk] = reflect.New(decoratorType.In(walk)).Elem()
+			if arg != nil {
+				in[walk].Set(reflect.ValueOf(arg))
+			}
+		}
+
+		results := reflect.ValueOf(decorator).Call(in)
+		if len(results) == 2 && !results[1].IsNil() {
+			return nil, res.fail(objType, results[1].Interface().(error))
+		}
+
+		constructed = results[0].Interface()
+	}
+	return constructed, nil
+}
@@ -0,0 +1,140 @@
+package wired_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/okke/wired"
+	"github.com/okke/wired/internal"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type plainGreeter struct{}
+
+func (plainGreeter *plainGreeter) Greet() string {
+	return "hello"
+}
+
+func newGreeter() greeter {
+	return &plainGreeter{}
+}
+
+type greetingPrefix string
+
+type prefixingGreeter struct {
+	next   greeter
+	prefix greetingPrefix
+}
+
+func (prefixingGreeter *prefixingGreeter) Greet() string {
+	return string(prefixingGreeter.prefix) + prefixingGreeter.next.Greet()
+}
+
+type shoutingGreeter struct {
+	next greeter
+}
+
+func (shoutingGreeter *shoutingGreeter) Greet() string {
+	return shoutingGreeter.next.Greet() + "!"
+}
+
+func TestDecoratorsShouldWrapInOrderOfRegistration(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newGreeter)
+		scope.Register(func() greetingPrefix { return "> " })
+		scope.Decorate(func(next greeter, prefix greetingPrefix) greeter {
+			return &prefixingGreeter{next: next, prefix: prefix}
+		})
+
+		scope.Go(func(sub wired.Scope) {
+			sub.Decorate(func(next greeter) greeter {
+				return &shoutingGreeter{next: next}
+			})
+
+			if greeting := wired.MustGet[greeter](sub).Greet(); greeting != "> hello!" {
+				t.Error("expected decorators of parent scope to be applied first, not", greeting)
+			}
+		})
+
+		if greeting := wired.MustGet[greeter](scope).Greet(); greeting != "> hello" {
+			t.Error("expected decorators of sub scope not to be applied, not", greeting)
+		}
+	})
+}
+
+func TestDecoratorShouldWrapBoundInterfaces(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterAs(func() *plainGreeter { return &plainGreeter{} }, wired.TypeOf[greeter]())
+		scope.Decorate(func(next greeter) greeter {
+			return &shoutingGreeter{next: next}
+		})
+
+		if greeting := wired.MustGet[greeter](scope).Greet(); greeting != "hello!" {
+			t.Error("expected bound interface to be decorated, not", greeting)
+		}
+	})
+}
+
+func TestDecoratorShouldReturnErrors(t *testing.T) {
+	failed := errors.New("no greetings today")
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newGreeter)
+		scope.Decorate(func(next greeter) (greeter, error) {
+			return nil, failed
+		})
+
+		if _, err := wired.Get[greeter](scope); !errors.Is(err, failed) {
+			t.Error("expected error of decorator, not", err)
+		}
+	})
+}
+
+func TestDecorateShouldPanicOnInvalidDecorator(t *testing.T) {
+	defer internal.ShouldPanic(t)()
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Decorate(func(next greeter) *plainGreeter { return nil })
+	})
+}
+
+type singletonGreeting struct {
+	wired.Singleton
+
+	text string
+}
+
+func TestDecoratorsOfSubScopeShouldWrapSingletonOfParent(t *testing.T) {
+	constructed := 0
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *singletonGreeting {
+			constructed++
+			return &singletonGreeting{text: "hello"}
+		})
+		scope.Decorate(func(next *singletonGreeting) *singletonGreeting {
+			return &singletonGreeting{text: "> " + next.text}
+		})
+
+		scope.Go(func(sub wired.Scope) {
+			sub.Decorate(func(next *singletonGreeting) *singletonGreeting {
+				return &singletonGreeting{text: next.text + "!"}
+			})
+
+			if greeting := wired.MustGet[*singletonGreeting](sub).text; greeting != "> hello!" {
+				t.Error("expected decorator of sub scope to wrap singleton of parent, not", greeting)
+			}
+		})
+
+		if greeting := wired.MustGet[*singletonGreeting](scope).text; greeting != "> hello" {
+			t.Error("expected decorator of sub scope not to be cached, not", greeting)
+		}
+	})
+
+	if constructed != 1 {
+		t.Error("expected singleton to be constructed once, not", constructed)
+	}
+}
@@ -23,8 +23,28 @@ func init() {
 // Apply factory creation logic
 //
 func (factory *factory) Apply(scope Scope, objType reflect.Type, constructor func() interface{}) interface{} {
+	return tryApply(factory, scope, objType, constructor)
+}
+
+// TryApply applies factory creation logic
+//
+func (factory *factory) TryApply(scope Scope, objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error) {
+
+	created := false
+	constructed, err := factory.singleton.TryApply(scope, objType, func() (interface{}, error) {
+		created = true
+		return constructor()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// constructor methods only need to be registered once
+	//
+	if !created {
+		return constructed, nil
+	}
 
-	constructed := factory.singleton.Apply(scope, objType, constructor)
 	constructedValue := reflect.ValueOf(constructed)
 	constructedType := reflect.TypeOf(constructed)
 
@@ -34,12 +54,15 @@ func (factory *factory) Apply(scope Scope, objType reflect.Type, constructor fun
 			factoryMethod := constructedValue.MethodByName(factoryMethodType.Name)
 
 			if factoryMethod.Kind() != reflect.Invalid {
-				scope.Register(factoryMethod.Interface())
+				scope.Register(&method{
+					receiver: constructedType,
+					name:     factoryMethodType.Name,
+					function: factoryMethod.Interface()})
 			}
 		}
 	}
 
-	return constructed
+	return constructed, nil
 }
 
 func (factory *factory) ShouldAutoConstruct() bool {
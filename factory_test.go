@@ -1,10 +1,12 @@
 package wired_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/okke/wired"
+	"github.com/okke/wired/internal"
 )
 
 type pepperFactory struct {
@@ -103,3 +105,84 @@ func TestMultiFactoryConstruction(t *testing.T) {
 
 	})
 }
+
+// --- test factory methods returning errors ---------
+
+type failingFactory struct {
+	wired.Factory
+}
+
+func (failingFactory *failingFactory) ConstructSoup() (*soup, error) {
+	return nil, errors.New("soup is cold")
+}
+
+func newFailingFactory() *failingFactory {
+	return &failingFactory{}
+}
+
+func TestFactoryMethodReturningError(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newFailingFactory)
+
+		if err := scope.TryInject(func(s *soup) {
+			t.Error("should not be called")
+		}); err == nil {
+			t.Error("expected factory method error to be returned")
+		}
+	})
+}
+
+type cloudFactory struct {
+	wired.Factory
+}
+
+func (cloudFactory *cloudFactory) ConstructAWS() *driver {
+	return &driver{name: "aws"}
+}
+
+func (cloudFactory *cloudFactory) ConstructAzure() *driver {
+	return &driver{name: "azure"}
+}
+
+func TestExcludeShouldLeaveOutObjectsOfFactory(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *cloudFactory { return &cloudFactory{} })
+		factory := wired.MustGet[*cloudFactory](scope)
+
+		scope.Go(func(sub wired.Scope) {
+			sub.Exclude(factory.ConstructAzure)
+
+			if drivers := wired.MustGet[[]*driver](sub); len(drivers) != 1 || drivers[0].name != "aws" {
+				t.Error("expected azure driver of factory to be excluded, not", drivers)
+			}
+		})
+
+		scope.Go(func(sub wired.Scope) {
+			sub.Exclude((*cloudFactory).ConstructAWS)
+
+			if drivers := wired.MustGet[[]*driver](sub); len(drivers) != 1 || drivers[0].name != "azure" {
+				t.Error("expected aws driver of factory to be excluded, not", drivers)
+			}
+		})
+
+		scope.Go(func(sub wired.Scope) {
+			sub.ExcludeKey(wired.TypeOf[*driver](), "azure")
+
+			if drivers := wired.MustGet[[]*driver](sub); len(drivers) != 1 || drivers[0].name != "aws" {
+				t.Error("expected azure driver of factory to be excluded by its key, not", drivers)
+			}
+		})
+
+		if drivers := wired.MustGet[[]*driver](scope); len(drivers) != 2 {
+			t.Error("expected both drivers of factory, not", drivers)
+		}
+	})
+}
+
+func TestExcludeShouldRejectMethodValuesCreatedByReflection(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		defer internal.ShouldPanic(t)()
+
+		scope.Exclude(reflect.ValueOf(&cloudFactory{}).MethodByName("ConstructAWS").Interface())
+	})
+}
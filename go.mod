@@ -0,0 +1,3 @@
+module github.com/okke/wired
+
+go 1.21
@@ -0,0 +1,484 @@
+package wired
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/okke/wired/internal"
+)
+
+// EdgeKind tells why one node depends on another
+//
+type EdgeKind string
+
+const (
+
+	// ArgumentEdge points from a constructed type to a constructor argument
+	//
+	ArgumentEdge EdgeKind = "argument"
+
+	// FieldEdge points from a constructed type to the type of a wired field
+	//
+	FieldEdge EdgeKind = "field"
+
+	// ElementEdge points from a slice or map to the type of one of its elements
+	//
+	ElementEdge EdgeKind = "element"
+
+	// ImplementationEdge points from an interface to the type bound to it
+	//
+	ImplementationEdge EdgeKind = "implementation"
+
+	// FactoryEdge points from a type to the factory whose Construct method constructs it
+	//
+	FactoryEdge EdgeKind = "factory"
+)
+
+// Node is a type that can be constructed, or a type that's needed by a
+// constructed type but has no constructor
+//
+type Node struct {
+
+	// ID identifies a node by its type, followed by the name its constructor
+	// was registered with, if any
+	//
+	ID string `json:"id"`
+
+	Type reflect.Type `json:"-"`
+	Name string       `json:"name,omitempty"`
+
+	// Constructor is the name of the constructor function and Source the file
+	// and line it's defined at. Both are empty when no constructor is known
+	//
+	Constructor string `json:"constructor,omitempty"`
+	Source      string `json:"source,omitempty"`
+
+	Singleton  bool `json:"singleton,omitempty"`
+	Factory    bool `json:"factory,omitempty"`
+	Aggregate  bool `json:"aggregate,omitempty"`  // slice or map of all constructors of a type
+	Unresolved bool `json:"unresolved,omitempty"` // type is needed but can not be constructed
+
+	// Scope tells where the constructor is registered, 0 is the scope the graph
+	// was requested for, 1 its parent and so on
+	//
+	Scope int `json:"scope"`
+
+	// Module is the name of the module that registered the constructor, if any
+	//
+	Module string `json:"module,omitempty"`
+
+	// Elements are the constructors of the values of a slice or map, in the order
+	// their objects are injected in a slice
+	//
+	Elements []*Element `json:"elements,omitempty"`
+}
+
+// Element is a constructor of one of the values of a slice or map
+//
+type Element struct {
+
+	// Node is the id of the node of the constructed value
+	//
+	Node        string `json:"node"`
+	Constructor string `json:"constructor"`
+	Priority    int    `json:"priority"`
+
+	// Key is the key of the element in a map, when it's known upfront
+	//
+	Key string `json:"key,omitempty"`
+
+	// Ordered tells the constructed object implements Orderer, so its priority
+	// is only known once it's constructed
+	//
+	Ordered bool `json:"ordered,omitempty"`
+}
+
+// Edge tells node From depends on node To
+//
+type Edge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  EdgeKind `json:"kind"`
+	Field string   `json:"field,omitempty"`
+}
+
+// Graph holds all types a scope and its parents know how to construct and
+// how they depend on each other. Nodes and edges are sorted, so graphs can
+// be compared
+//
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// fieldResolver can be implemented by struct decoration tags that construct
+// field values, to tell which type they will construct for a field
+//
+type fieldResolver interface {
+	fieldDependency(structType reflect.Type, fieldType reflect.StructField) (reflect.Type, string, bool)
+}
+
+func nodeID(objType reflect.Type, name string) string {
+	if name == "" {
+		return fmt.Sprint(objType)
+	}
+	return fmt.Sprintf("%v@%s", objType, name)
+}
+
+// constructorSource returns the file and line of a constructor
+//
+func constructorSource(constructor interface{}) string {
+	switch constructor := constructor.(type) {
+	case *method:
+		if reflected, found := constructor.receiver.MethodByName(constructor.name); found {
+			return internal.FunctionSource(reflected.Func.Interface())
+		}
+		return ""
+	case *tagged:
+		return constructorSource(constructor.constructor)
+	}
+	return internal.FunctionSource(constructor)
+}
+
+type graphBuilder struct {
+	scope      *scope
+	nodes      map[string]*Node
+	registered map[string]bool // nodes that are added for a registered constructor
+	edges      map[Edge]bool
+}
+
+// Graph returns the dependency graph of a scope and its parents. No
+// constructor is called while building it
+//
+func (scope *scope) Graph() *Graph {
+
+	builder := &graphBuilder{
+		scope:      scope,
+		nodes:      make(map[string]*Node),
+		registered: make(map[string]bool),
+		edges:      make(map[Edge]bool)}
+
+	depth := 0
+	for walk := scope; walk != nil; walk = walk.parent {
+		for _, registration := range walk.sortedConstructors() {
+			builder.addRegistration(walk, registration, depth)
+		}
+		depth++
+	}
+
+	return builder.graph()
+}
+
+func (builder *graphBuilder) addRegistration(registrar *scope, registration registration, depth int) {
+
+	id := nodeID(registration.objType, registration.name)
+
+	// constructors of sub scopes hide the ones of their parents
+	//
+	if builder.registered[id] {
+		return
+	}
+
+	node := &Node{
+		ID:     id,
+		Type:   registration.objType,
+		Name:   registration.name,
+		Scope:  depth,
+		Module: registrar.moduleOf(registration.binding)}
+	builder.nodes[id] = node
+	builder.registered[id] = true
+
+	switch constructor := registration.constructor.(type) {
+	case *aggregator:
+		node.Aggregate = true
+		builder.addAggregator(node, constructor)
+	case *alias:
+		builder.addEdge(Edge{From: id, To: builder.reference(constructor.target, constructor.name), Kind: ImplementationEdge})
+	default:
+		builder.addConstructor(node, constructor)
+	}
+}
+
+func (builder *graphBuilder) addAggregator(node *Node, aggregated *aggregator) {
+	elementType := aggregated.objType.Elem()
+
+	for _, element := range builder.scope.elementsOf(aggregated.objType) {
+		to := builder.reference(elementType, element.name)
+		builder.addEdge(Edge{From: node.ID, To: to, Kind: ElementEdge})
+
+		listed := &Element{Node: to, Priority: element.priority, Ordered: elementType.Implements(ordererType)}
+
+		if element.key != nil {
+			listed.Key = fmt.Sprint(element.key)
+		}
+
+		if bound, ok := element.constructor.(*alias); ok {
+			listed.Constructor = nodeID(bound.target, bound.name)
+			listed.Ordered = bound.target.Implements(ordererType)
+		} else {
+			listed.Constructor = constructorName(element.constructor)
+			if constructorType, err := functionType(element.constructor); err == nil {
+				if outType, constructs := constructedType(constructorType); constructs {
+					listed.Ordered = outType.Implements(ordererType)
+				}
+			}
+		}
+
+		node.Elements = append(node.Elements, listed)
+	}
+
+	sort.SliceStable(node.Elements, func(i, j int) bool {
+		return node.Elements[i].Priority < node.Elements[j].Priority
+	})
+}
+
+func (builder *graphBuilder) addConstructor(node *Node, constructor interface{}) {
+
+	node.Constructor = constructorName(constructor)
+	node.Source = constructorSource(constructor)
+
+	if method, ok := constructor.(*method); ok {
+		builder.addEdge(Edge{From: node.ID, To: builder.reference(method.receiver, ""), Kind: FactoryEdge})
+	}
+
+	builder.addArguments(node.ID, constructor)
+	builder.addDecorators(node.ID, node.Type)
+
+	if tag, found := constructionTag(constructor, node.Type); found {
+		switch tag.(type) {
+		case *singleton:
+			node.Singleton = true
+		case *factory:
+			node.Singleton = true
+			node.Factory = true
+			builder.addFactoryMethods(node.Type, node.Scope)
+		}
+	}
+
+	builder.addFields(node.ID, node.Type)
+}
+
+func (builder *graphBuilder) addArguments(id string, constructor interface{}) {
+	constructorType, err := functionType(constructor)
+	if err != nil {
+		return
+	}
+
+	for walk := 0; walk < constructorType.NumIn(); walk++ {
+		builder.addEdge(Edge{From: id, To: builder.reference(constructorType.In(walk), ""), Kind: ArgumentEdge})
+	}
+}
+
+// addDecorators adds the arguments injected into the decorators of a type, which
+// are resolved whenever an object of that type is constructed
+//
+func (builder *graphBuilder) addDecorators(id string, objType reflect.Type) {
+	for _, decorator := range builder.scope.decoratorsOf(objType) {
+		decoratorType := reflect.TypeOf(decorator)
+		for walk := 1; walk < decoratorType.NumIn(); walk++ {
+			builder.addEdge(Edge{From: id, To: builder.reference(decoratorType.In(walk), ""), Kind: ArgumentEdge})
+		}
+	}
+}
+
+// addFactoryMethods adds the Construct methods of a factory, since these are only
+// registered once the factory is constructed
+//
+func (builder *graphBuilder) addFactoryMethods(factoryType reflect.Type, depth int) {
+	for walk := 0; walk < factoryType.NumMethod(); walk++ {
+		factoryMethod := factoryType.Method(walk)
+		if !strings.HasPrefix(factoryMethod.Name, "Construct") {
+			continue
+		}
+
+		// method types include their receiver as first argument
+		//
+		methodType := factoryMethod.Type
+		outType, constructs := constructedType(methodType)
+		if !constructs {
+			continue
+		}
+
+		id := nodeID(outType, "")
+		if builder.registered[id] {
+			continue
+		}
+
+		node := &Node{
+			ID:          id,
+			Type:        outType,
+			Constructor: constructorName(&method{receiver: factoryType, name: factoryMethod.Name}),
+			Source:      internal.FunctionSource(factoryMethod.Func.Interface()),
+			Scope:       depth}
+		builder.nodes[id] = node
+		builder.registered[id] = true
+
+		builder.addEdge(Edge{From: id, To: nodeID(factoryType, ""), Kind: FactoryEdge})
+		for arg := 1; arg < methodType.NumIn(); arg++ {
+			builder.addEdge(Edge{From: id, To: builder.reference(methodType.In(arg), ""), Kind: ArgumentEdge})
+		}
+		builder.addFields(id, outType)
+	}
+}
+
+func (builder *graphBuilder) addFields(id string, objType reflect.Type) {
+
+	if objType.Kind() == reflect.Ptr {
+		objType = objType.Elem()
+	}
+
+	if objType.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, decorator := range FindStructDecorationTags(objType) {
+		if resolver, ok := decorator.(fieldResolver); ok {
+			for walk := 0; walk < objType.NumField(); walk++ {
+				fieldType := objType.Field(walk)
+				if dependency, name, wired := resolver.fieldDependency(objType, fieldType); wired {
+					builder.addEdge(Edge{From: id, To: builder.reference(dependency, name), Kind: FieldEdge, Field: fieldType.Name})
+				}
+			}
+		}
+	}
+}
+
+// reference returns the id of a node a constructor depends on. Nodes of types that
+// are not registered yet are added without a constructor, they're replaced when
+// a constructor is found later on
+//
+func (builder *graphBuilder) reference(objType reflect.Type, name string) string {
+	id := nodeID(objType, name)
+	if _, found := builder.nodes[id]; !found {
+		builder.nodes[id] = &Node{
+			ID:         id,
+			Type:       objType,
+			Name:       name,
+			Unresolved: !builder.scope.canResolve(objType, name)}
+	}
+	return id
+}
+
+func (builder *graphBuilder) addEdge(edge Edge) {
+	builder.edges[edge] = true
+}
+
+func (builder *graphBuilder) graph() *Graph {
+	graph := &Graph{
+		Nodes: make([]*Node, 0, len(builder.nodes)),
+		Edges: make([]*Edge, 0, len(builder.edges))}
+
+	// slices and maps are known for every registered type, only
+	// show the ones that are actually needed
+	//
+	needed := make(map[string]bool)
+	for edge := range builder.edges {
+		needed[edge.To] = true
+	}
+	for edge := range builder.edges {
+		if node := builder.nodes[edge.From]; node.Aggregate && !needed[node.ID] {
+			delete(builder.edges, edge)
+		}
+	}
+
+	for _, node := range builder.nodes {
+		if !node.Aggregate || needed[node.ID] {
+			graph.Nodes = append(graph.Nodes, node)
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+
+	for edge := range builder.edges {
+		edge := edge
+		graph.Edges = append(graph.Edges, &edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		left, right := graph.Edges[i], graph.Edges[j]
+		if left.From != right.From {
+			return left.From < right.From
+		}
+		if left.To != right.To {
+			return left.To < right.To
+		}
+		if left.Kind != right.Kind {
+			return left.Kind < right.Kind
+		}
+		return left.Field < right.Field
+	})
+
+	return graph
+}
+
+// JSON renders a graph as indented JSON
+//
+func (graph *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(graph, "", "  ")
+}
+
+// DOT renders a graph in the Graphviz DOT language
+//
+func (graph *Graph) DOT() string {
+	var dot strings.Builder
+
+	dot.WriteString("digraph wired {\n")
+	dot.WriteString("  node [shape=box];\n")
+
+	modules := make(map[string][]string)
+	for _, node := range graph.Nodes {
+		if node.Module != "" {
+			modules[node.Module] = append(modules[node.Module], strconv.Quote(node.ID))
+		}
+
+		label := node.ID
+		if node.Constructor != "" {
+			label = fmt.Sprintf("%s\n%s", label, node.Constructor)
+		}
+
+		attributes := []string{"label=" + strconv.Quote(label)}
+		switch {
+		case node.Factory:
+			attributes = append(attributes, "shape=component")
+		case node.Aggregate:
+			attributes = append(attributes, "shape=folder")
+		case node.Unresolved:
+			attributes = append(attributes, "style=dashed")
+		}
+		if node.Singleton {
+			attributes = append(attributes, "peripheries=2")
+		}
+
+		fmt.Fprintf(&dot, "  %s [%s];\n", strconv.Quote(node.ID), strings.Join(attributes, ", "))
+	}
+
+	// group nodes of the same module in a cluster
+	//
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for walk, name := range names {
+		fmt.Fprintf(&dot, "  subgraph cluster_%d {\n", walk)
+		fmt.Fprintf(&dot, "    label=%s;\n", strconv.Quote(name))
+		fmt.Fprintf(&dot, "    %s;\n", strings.Join(modules[name], "; "))
+		dot.WriteString("  }\n")
+	}
+
+	for _, edge := range graph.Edges {
+		label := string(edge.Kind)
+		if edge.Field != "" {
+			label = fmt.Sprintf("%s %s", label, edge.Field)
+		}
+		fmt.Fprintf(&dot, "  %s -> %s [label=%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strconv.Quote(label))
+	}
+
+	dot.WriteString("}\n")
+	return dot.String()
+}
@@ -0,0 +1,197 @@
+package wired_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type graphEngine struct {
+}
+
+type graphWheel struct {
+}
+
+type graphCar struct {
+	wired.AutoWire
+
+	Engine *graphEngine
+	Spare  *graphWheel `autowire:"spare"`
+}
+
+type graphGarage struct {
+	wired.Singleton
+}
+
+type graphFactory struct {
+	wired.Factory
+}
+
+func (graphFactory *graphFactory) ConstructWheel(garage *graphGarage) *graphWheel {
+	return &graphWheel{}
+}
+
+func newGraphEngine() *graphEngine {
+	return &graphEngine{}
+}
+
+func newGraphCar(wheels []*graphWheel) *graphCar {
+	return &graphCar{}
+}
+
+func findEdge(graph *wired.Graph, from, to string, kind wired.EdgeKind) *wired.Edge {
+	for _, edge := range graph.Edges {
+		if edge.From == from && edge.To == to && edge.Kind == kind {
+			return edge
+		}
+	}
+	return nil
+}
+
+func findNode(graph *wired.Graph, id string) *wired.Node {
+	for _, node := range graph.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	return nil
+}
+
+func TestGraph(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newGraphEngine)
+		scope.Register(func() *graphGarage { return &graphGarage{} })
+
+		scope.Go(func(inner wired.Scope) {
+			inner.Register(func() *graphFactory { return &graphFactory{} })
+			inner.Register(newGraphCar)
+
+			graph := inner.Graph()
+
+			if car := findNode(graph, "*wired_test.graphCar"); car == nil {
+				t.Fatal("expected a node for the car")
+			} else if !strings.HasSuffix(car.Constructor, "newGraphCar") || !strings.Contains(car.Source, "graph_test.go:") {
+				t.Error("expected constructor and source of the car, not", car.Constructor, car.Source)
+			} else if car.Scope != 0 {
+				t.Error("expected car to be registered in the inner scope, not", car.Scope)
+			}
+
+			if engine := findNode(graph, "*wired_test.graphEngine"); engine == nil || engine.Scope != 1 {
+				t.Error("expected engine to be registered in the parent scope, not", engine)
+			}
+
+			if garage := findNode(graph, "*wired_test.graphGarage"); garage == nil || !garage.Singleton {
+				t.Error("expected garage to be a singleton, not", garage)
+			}
+
+			if factory := findNode(graph, "*wired_test.graphFactory"); factory == nil || !factory.Factory {
+				t.Error("expected a factory, not", factory)
+			}
+
+			if wheel := findNode(graph, "*wired_test.graphWheel"); wheel == nil || !strings.Contains(wheel.Constructor, "ConstructWheel") {
+				t.Error("expected wheel to be constructed by the factory, not", wheel)
+			}
+
+			if spare := findNode(graph, "*wired_test.graphWheel@spare"); spare == nil || !spare.Unresolved {
+				t.Error("expected spare wheel to be unresolved, not", spare)
+			}
+
+			if findNode(graph, "[]*wired_test.graphCar") != nil {
+				t.Error("expected slices that are not needed to be left out")
+			}
+
+			expected := []struct {
+				from, to string
+				kind     wired.EdgeKind
+			}{
+				{"*wired_test.graphCar", "[]*wired_test.graphWheel", wired.ArgumentEdge},
+				{"*wired_test.graphCar", "*wired_test.graphEngine", wired.FieldEdge},
+				{"*wired_test.graphCar", "*wired_test.graphWheel@spare", wired.FieldEdge},
+				{"*wired_test.graphWheel", "*wired_test.graphFactory", wired.FactoryEdge},
+				{"*wired_test.graphWheel", "*wired_test.graphGarage", wired.ArgumentEdge},
+				{"[]*wired_test.graphWheel", "*wired_test.graphWheel", wired.ElementEdge},
+			}
+			for _, edge := range expected {
+				if findEdge(graph, edge.from, edge.to, edge.kind) == nil {
+					t.Error("expected", edge.kind, "edge from", edge.from, "to", edge.to)
+				}
+			}
+
+			if field := findEdge(graph, "*wired_test.graphCar", "*wired_test.graphEngine", wired.FieldEdge); field != nil && field.Field != "Engine" {
+				t.Error("expected edge to name its field, not", field.Field)
+			}
+		})
+	})
+}
+
+func TestGraphShouldListElementsInOrder(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newGraphCar)
+		scope.Register(func() *graphWheel { return &graphWheel{} })
+		scope.RegisterWithPriority(-1, func() *graphWheel { return &graphWheel{} })
+
+		for _, node := range scope.Graph().Nodes {
+			if node.Type != reflect.TypeOf([]*graphWheel{}) {
+				continue
+			}
+
+			if len(node.Elements) != 2 || node.Elements[0].Priority != -1 || node.Elements[1].Priority != 0 {
+				t.Error("expected elements in order of priority, not", node.Elements)
+			}
+			return
+		}
+		t.Error("expected a node for the slice of wheels")
+	})
+}
+
+func TestGraphRendering(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newGraphEngine)
+		scope.Register(newGraphCar)
+
+		graph := scope.Graph()
+
+		dot := graph.DOT()
+		if !strings.HasPrefix(dot, "digraph wired {") {
+			t.Error("expected a digraph, not", dot)
+		}
+		if !strings.Contains(dot, `"*wired_test.graphCar" -> "*wired_test.graphEngine" [label="field Engine"];`) {
+			t.Error("expected field edge in", dot)
+		}
+
+		rendered, err := graph.JSON()
+		if err != nil {
+			t.Fatal("expected graph to render as JSON, not", err)
+		}
+
+		parsed := &wired.Graph{}
+		if err := json.Unmarshal(rendered, parsed); err != nil {
+			t.Fatal("expected valid JSON, not", err)
+		}
+		if len(parsed.Nodes) != len(graph.Nodes) || len(parsed.Edges) != len(graph.Edges) {
+			t.Error("expected all nodes and edges to be rendered")
+		}
+
+		if again, _ := scope.Graph().JSON(); string(again) != string(rendered) {
+			t.Error("expected graphs to be rendered deterministically")
+		}
+	})
+}
+
+func TestGraphShouldContainArgumentsOfDecorators(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *graphEngine { return &graphEngine{} })
+		scope.Decorate(func(engine *graphEngine, garage *graphGarage) *graphEngine { return engine })
+
+		graph := scope.Graph()
+		if findEdge(graph, "*wired_test.graphEngine", "*wired_test.graphGarage", wired.ArgumentEdge) == nil {
+			t.Error("expected an argument edge to the garage needed by a decorator")
+		}
+		if garage := findNode(graph, "*wired_test.graphGarage"); garage == nil || !garage.Unresolved {
+			t.Error("expected garage to be unresolved, not", garage)
+		}
+	})
+}
@@ -1,8 +1,11 @@
 package internal
 
 import (
+	"fmt"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 )
 
 // NilValue can be used where a Value is required but no value can be determined
@@ -15,17 +18,16 @@ var NilValue = reflect.ValueOf(nil)
 func GetFieldValueByReflection(obj reflect.Value, field reflect.Value, fieldType reflect.StructField) interface{} {
 
 	if field.CanSet() {
-		if field.Kind() == reflect.Ptr && field.IsNil() {
+		if isNilPointerOrFunc(field) {
 			return nil
 		}
 		return field.Interface()
 	}
 
-	name := strings.Join([]string{"Get", strings.Title(fieldType.Name)}, "")
-	getter := obj.Addr().MethodByName(name)
-	if getter.Kind() != reflect.Invalid {
-		result := getter.Call([]reflect.Value{})
-		if result[0].Kind() == reflect.Ptr && result[0].IsNil() {
+	structPtr := obj.Addr()
+	if getter, found := accessor(structPtr.Type(), "Get", fieldType.Name); found {
+		result := structPtr.Method(getter).Call([]reflect.Value{})
+		if isNilPointerOrFunc(result[0]) {
 			return nil
 		}
 		return result[0].Interface()
@@ -33,6 +35,47 @@ func GetFieldValueByReflection(obj reflect.Value, field reflect.Value, fieldType
 	return nil
 }
 
+type accessorKey struct {
+	structPtr reflect.Type
+	prefix    string
+	field     string
+}
+
+var accessors sync.Map // maps accessorKey to the index of a method, or -1 when not found
+
+// accessor returns the index of a getter or setter method of a field, like GetName
+// or SetName. Indexes are cached since looking up methods by name is expensive
+//
+func accessor(structPtr reflect.Type, prefix string, field string) (int, bool) {
+	key := accessorKey{structPtr: structPtr, prefix: prefix, field: field}
+
+	if index, found := accessors.Load(key); found {
+		return index.(int), index.(int) >= 0
+	}
+
+	index := -1
+	if method, found := structPtr.MethodByName(strings.Join([]string{prefix, strings.Title(field)}, "")); found {
+		index = method.Index
+	}
+	accessors.Store(key, index)
+	return index, index >= 0
+}
+
+func isNilPointerOrFunc(value reflect.Value) bool {
+	return (value.Kind() == reflect.Ptr || value.Kind() == reflect.Func) && value.IsNil()
+}
+
+// CanSetField tells whether a field of a struct can be set by SetFieldValueByReflection
+//
+func CanSetField(structType reflect.Type, fieldType reflect.StructField) bool {
+	if fieldType.PkgPath == "" {
+		return true
+	}
+
+	_, found := accessor(reflect.PtrTo(structType), "Set", fieldType.Name)
+	return found
+}
+
 // SetFieldValueByReflection will set a fields value through reflection. Either by
 // accessing it as a public field. Or by using its setter method
 //
@@ -40,10 +83,9 @@ func SetFieldValueByReflection(objValue reflect.Value, field reflect.Value, fiel
 	if field.CanSet() {
 		field.Set(value)
 	} else {
-		name := strings.Join([]string{"Set", strings.Title(fieldType.Name)}, "")
-		setter := objValue.Addr().MethodByName(name)
-		if setter.Kind() != reflect.Invalid {
-			setter.Call([]reflect.Value{value})
+		structPtr := objValue.Addr()
+		if setter, found := accessor(structPtr.Type(), "Set", fieldType.Name); found {
+			structPtr.Method(setter).Call([]reflect.Value{value})
 		}
 	}
 }
@@ -88,3 +130,34 @@ func CreateMapWithValues(mapType reflect.Type, values ...interface{}) reflect.Va
 	}
 	return mapping
 }
+
+// FunctionName returns the fully qualified name of a function. For values
+// that are not a function, their type is returned instead
+//
+func FunctionName(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		return fmt.Sprint(reflect.TypeOf(fn))
+	}
+
+	if function := runtime.FuncForPC(value.Pointer()); function != nil {
+		return strings.TrimSuffix(function.Name(), "-fm")
+	}
+	return value.Type().String()
+}
+
+// FunctionSource returns the file and line a function is defined at, formatted
+// as file:line. An empty string is returned when the source is not known
+//
+func FunctionSource(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		return ""
+	}
+
+	if function := runtime.FuncForPC(value.Pointer()); function != nil {
+		file, line := function.FileLine(function.Entry())
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package wired
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Provider is a function that constructs an object of type T when it's called
+// for the first time, and returns this same object afterwards. Constructor
+// arguments and auto-wired fields of type Provider[T] are provided by wired when
+// it knows how to construct T, just like arguments and fields of type func() T
+// and func() (T, error).
+//
+// Providers break dependency cycles and postpone construction of objects that
+// are not always needed. A provider panics when T can not be constructed, a
+// func() (T, error) returns the error instead
+//
+type Provider[T any] func() T
+
+// providedType returns the type a provider function type provides
+//
+func providedType(objType reflect.Type) (reflect.Type, bool) {
+	if objType.Kind() != reflect.Func || objType.NumIn() != 0 {
+		return nil, false
+	}
+
+	switch objType.NumOut() {
+	case 1:
+		return objType.Out(0), objType.Out(0) != errorType
+	case 2:
+		return objType.Out(0), objType.Out(1) == errorType
+	}
+	return nil, false
+}
+
+// provider creates a function of given provider type that constructs the provided type
+// within this scope. When called after the object it's provided to is built, construction
+// starts a new resolution, so providers do not take part in the dependency cycles of the
+// resolution they're created by. When called while this object is still being built, the
+// resolution continues so cycles are reported instead of waiting for a singleton forever
+//
+func (scope *scope) provider(res *resolution, objType reflect.Type, name string) (interface{}, bool) {
+
+	provided, ok := providedType(objType)
+	if !ok || !scope.canResolve(provided, name) {
+		return nil, false
+	}
+
+	var lock sync.Mutex
+	var constructed reflect.Value
+
+	provide := func(args []reflect.Value) []reflect.Value {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if !constructed.IsValid() {
+			continued := newResolution().withContext(res.ctx)
+			if res.parent != nil && res.parent.isBuilding() {
+				continued = res
+			}
+
+			object, err := scope.constructByType(continued, provided, name, dependency{})
+			if err != nil {
+				if objType.NumOut() == 1 {
+					panic(err)
+				}
+				return []reflect.Value{reflect.Zero(provided), reflect.ValueOf(&err).Elem()}
+			}
+
+			constructed = reflect.New(provided).Elem()
+			if object != nil {
+				constructed.Set(reflect.ValueOf(object))
+			}
+		}
+
+		if objType.NumOut() == 1 {
+			return []reflect.Value{constructed}
+		}
+		return []reflect.Value{constructed, reflect.Zero(errorType)}
+	}
+
+	return reflect.MakeFunc(objType, provide).Interface(), true
+}
@@ -0,0 +1,132 @@
+package wired_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okke/wired"
+)
+
+type lazyA struct {
+	b *lazyB
+}
+
+type lazyB struct {
+	a func() *lazyA
+}
+
+type expensive struct {
+}
+
+type usesExpensive struct {
+	wired.AutoWire
+
+	Expensive wired.Provider[*expensive]
+}
+
+var expensiveConstructions int
+
+func newExpensive() *expensive {
+	expensiveConstructions++
+	return &expensive{}
+}
+
+func TestProviderShouldBreakCycles(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func(b *lazyB) *lazyA { return &lazyA{b: b} })
+		scope.Register(func(a func() *lazyA) *lazyB { return &lazyB{a: a} })
+
+		scope.Inject(func(a *lazyA) {
+			if a.b.a() == nil || a.b.a().b == nil {
+				t.Error("expected provider to construct an a")
+			}
+		})
+	})
+}
+
+func TestProviderShouldConstructOnFirstCall(t *testing.T) {
+	expensiveConstructions = 0
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newExpensive)
+		scope.Register(func() *usesExpensive { return &usesExpensive{} })
+
+		scope.Inject(func(uses *usesExpensive) {
+			if expensiveConstructions != 0 {
+				t.Error("expected construction to be postponed")
+			}
+
+			if uses.Expensive() != uses.Expensive() {
+				t.Error("expected provider to return the same object")
+			}
+
+			if expensiveConstructions != 1 {
+				t.Error("expected one construction, not", expensiveConstructions)
+			}
+		})
+
+		if err := scope.Validate(); err != nil {
+			t.Error("expected providers to validate, not", err)
+		}
+	})
+}
+
+func TestProviderShouldReportErrors(t *testing.T) {
+	failure := errors.New("too expensive")
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() (*expensive, error) { return nil, failure })
+
+		scope.Inject(func(provide func() (*expensive, error)) {
+			if _, err := provide(); !errors.Is(err, failure) {
+				t.Error("expected provider to return the constructor error, not", err)
+			}
+		})
+
+		if err := scope.TryInject(func(provide func() *unknownStruct) {}); err == nil {
+			t.Error("expected providers of unknown types to be unknown")
+		}
+	})
+}
+
+type eagerSingleton struct {
+	wired.Singleton
+
+	dependency *eagerDependency
+}
+
+type eagerDependency struct {
+	singleton *eagerSingleton
+}
+
+func TestProviderCalledDuringConstructionShouldReportCycle(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func(dependency func() (*eagerDependency, error)) (*eagerSingleton, error) {
+			constructed, err := dependency()
+			if err != nil {
+				return nil, err
+			}
+			return &eagerSingleton{dependency: constructed}, nil
+		})
+		scope.Register(func(singleton *eagerSingleton) *eagerDependency {
+			return &eagerDependency{singleton: singleton}
+		})
+
+		resolved := make(chan error, 1)
+		go func() {
+			_, err := wired.Get[*eagerSingleton](scope)
+			resolved <- err
+		}()
+
+		select {
+		case err := <-resolved:
+			var cycle *wired.CycleError
+			if !errors.As(err, &cycle) {
+				t.Error("expected a dependency cycle, not", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected a dependency cycle, not waiting for the singleton")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package wired
+
+import (
+	"io"
+	"sync"
+)
+
+// Initializer can be implemented by objects that need to initialize themselves
+// after they have been constructed and all their fields have been wired
+//
+type Initializer interface {
+	Init() error
+}
+
+// Starter can be implemented by objects that need to be started once all objects
+// constructed along with them have been initialized. Start is called right before
+// constructed objects are handed over by Inject, Construct or ConstructByType
+//
+type Starter interface {
+	Start() error
+}
+
+// start starts an object exactly once. When starting fails, the object is
+// started again the next time it's handed over
+//
+type start struct {
+	starter Starter
+	lock    sync.Mutex
+	started bool
+}
+
+func (start *start) run() error {
+	start.lock.Lock()
+	defer start.lock.Unlock()
+
+	if start.started {
+		return nil
+	}
+	if err := start.starter.Start(); err != nil {
+		return err
+	}
+	start.started = true
+	return nil
+}
+
+// closer returns a function that closes given object. Objects can be closed
+// when they implement io.Closer or have a Close method without results
+//
+func closer(obj interface{}) (func() error, bool) {
+
+	if closer, ok := obj.(io.Closer); ok {
+		return closer.Close, true
+	}
+
+	if closer, ok := obj.(interface{ Close() }); ok {
+		return func() error {
+			closer.Close()
+			return nil
+		}, true
+	}
+
+	return nil, false
+}
+
+// initialize calls the Init method of a constructed object and
+// remembers the object needs to be started
+//
+func (scope *scope) initialize(res *resolution, obj interface{}) error {
+
+	if initializer, ok := obj.(Initializer); ok {
+		if err := initializer.Init(); err != nil {
+			return res.fail(res.objType, err)
+		}
+	}
+
+	if starter, ok := obj.(Starter); ok {
+		res.session.add([]*start{{starter: starter}})
+	}
+
+	return nil
+}
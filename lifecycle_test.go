@@ -0,0 +1,326 @@
+package wired_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type lifecycleEvents struct {
+	events []string
+}
+
+func (lifecycleEvents *lifecycleEvents) add(event string) {
+	lifecycleEvents.events = append(lifecycleEvents.events, event)
+}
+
+type engine struct {
+	log *lifecycleEvents
+}
+
+func (engine *engine) Init() error {
+	engine.log.add("init engine")
+	return nil
+}
+
+func (engine *engine) Start() error {
+	engine.log.add("start engine")
+	return nil
+}
+
+type car struct {
+	wired.AutoWire
+
+	Engine *engine
+	log    *lifecycleEvents
+}
+
+func (car *car) Init() error {
+	if car.Engine == nil {
+		return errors.New("car has no engine")
+	}
+	car.log.add("init car")
+	return nil
+}
+
+func (car *car) Start() error {
+	car.log.add("start car")
+	return nil
+}
+
+func TestInitAndStartShouldBeCalledInOrder(t *testing.T) {
+	log := &lifecycleEvents{}
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *engine {
+			log.add("construct engine")
+			return &engine{log: log}
+		})
+		scope.Register(func() *car {
+			log.add("construct car")
+			return &car{log: log}
+		})
+
+		scope.Inject(func(car *car) {
+			log.add("use car")
+		})
+	})
+
+	expected := []string{
+		"construct car",
+		"construct engine",
+		"init engine",
+		"init car",
+		"start engine",
+		"start car",
+		"use car"}
+
+	if len(log.events) != len(expected) {
+		t.Fatal("expected", expected, "not", log.events)
+	}
+
+	for i, event := range expected {
+		if log.events[i] != event {
+			t.Error("expected", event, "not", log.events[i])
+		}
+	}
+}
+
+type brokenEngine struct {
+	wired.Singleton
+}
+
+var brokenEngineInits = 0
+
+func (brokenEngine *brokenEngine) Init() error {
+	brokenEngineInits = brokenEngineInits + 1
+	if brokenEngineInits == 1 {
+		return errors.New("engine does not want to start")
+	}
+	return nil
+}
+
+func newBrokenEngine() *brokenEngine {
+	return &brokenEngine{}
+}
+
+func TestFailingInitShouldStopConstruction(t *testing.T) {
+	brokenEngineInits = 0
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newBrokenEngine)
+
+		if err := scope.TryInject(func(engine *brokenEngine) {
+			t.Error("should not be called")
+		}); err == nil {
+			t.Error("expected init to fail")
+		}
+
+		// failing singleton should not be remembered
+		//
+		if err := scope.TryInject(func(engine *brokenEngine) {}); err != nil {
+			t.Error("expected init to succeed, not", err)
+		}
+	})
+}
+
+type stallingEngine struct {
+}
+
+func (stallingEngine *stallingEngine) Start() error {
+	return errors.New("stalled")
+}
+
+func TestFailingStartShouldBeReported(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *stallingEngine { return &stallingEngine{} })
+
+		if err := scope.TryInject(func(engine *stallingEngine) {
+			t.Error("should not be called")
+		}); err == nil {
+			t.Error("expected start to fail")
+		}
+	})
+}
+
+type startedEngine struct {
+	wired.Singleton
+
+	starts int
+}
+
+func (startedEngine *startedEngine) Start() error {
+	startedEngine.starts++
+	return nil
+}
+
+type brokenGearbox struct {
+}
+
+type startedCar struct {
+}
+
+func TestSingletonShouldBeStartedWhenSiblingFails(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *startedEngine { return &startedEngine{} })
+		scope.Register(func() (*brokenGearbox, error) { return nil, errors.New("broken") })
+		scope.Register(func(engine *startedEngine, gearbox *brokenGearbox) *startedCar { return &startedCar{} })
+
+		if err := scope.TryInject(func(car *startedCar) {
+			t.Error("should not be called")
+		}); err == nil {
+			t.Error("expected gearbox to fail")
+		}
+
+		scope.Inject(func(engine *startedEngine) {
+			if engine.starts != 1 {
+				t.Errorf("expected engine to be started once, not %d times", engine.starts)
+			}
+		})
+
+		scope.Inject(func(engine *startedEngine) {
+			if engine.starts != 1 {
+				t.Errorf("expected engine to be started once, not %d times", engine.starts)
+			}
+		})
+	})
+}
+
+type stallingSingleton struct {
+	wired.Singleton
+
+	starts int
+}
+
+func (stallingSingleton *stallingSingleton) Start() error {
+	stallingSingleton.starts++
+	return errors.New("stalled")
+}
+
+func TestFailingStartOfSingletonShouldBeReportedEveryTime(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *stallingSingleton { return &stallingSingleton{} })
+
+		for i := 0; i < 2; i++ {
+			if err := scope.TryInject(func(stalling *stallingSingleton) {
+				t.Error("should not be called")
+			}); err == nil {
+				t.Errorf("expected start %d to fail", i+1)
+			}
+		}
+
+		stalling, _ := scope.FindSingleton(reflect.TypeOf(&stallingSingleton{}))
+		if starts := stalling.(*stallingSingleton).starts; starts != 2 {
+			t.Errorf("expected start to be retried, started %d times", starts)
+		}
+	})
+}
+
+type closeLog struct {
+	closed []string
+}
+
+type closingResource struct {
+	name string
+	log  *closeLog
+	err  error
+}
+
+func (closingResource *closingResource) Close() error {
+	closingResource.log.closed = append(closingResource.log.closed, closingResource.name)
+	return closingResource.err
+}
+
+type closingDB struct {
+	wired.Singleton
+	closingResource
+}
+
+type closingTx struct {
+	wired.Singleton
+	closingResource
+}
+
+type closingTempDir struct {
+	wired.Singleton
+	closingResource
+}
+
+func TestCloseShouldCloseInReverseOrder(t *testing.T) {
+	log := &closeLog{}
+
+	err := wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *closingDB {
+			return &closingDB{closingResource: closingResource{name: "db", log: log}}
+		})
+		scope.Register(func(db *closingDB) *closingTx {
+			return &closingTx{closingResource: closingResource{name: "tx", log: log, err: errors.New("rollback failed")}}
+		})
+		scope.Register(func() *closingTempDir {
+			return &closingTempDir{closingResource: closingResource{name: "tmp", log: log, err: errors.New("busy")}}
+		})
+
+		scope.Inject(func(tx *closingTx, tmp *closingTempDir) {
+			if len(log.closed) != 0 {
+				t.Error("nothing should be closed yet")
+			}
+		})
+	})
+
+	expected := []string{"tmp", "tx", "db"}
+	if len(log.closed) != len(expected) {
+		t.Fatal("expected", expected, "to be closed, not", log.closed)
+	}
+	for i, name := range expected {
+		if log.closed[i] != name {
+			t.Error("expected", name, "to be closed, not", log.closed[i])
+		}
+	}
+
+	var errs wired.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Error("expected two errors, not", err)
+	}
+}
+
+func TestSubScopeShouldOnlyCloseItsOwnSingletons(t *testing.T) {
+	log := &closeLog{}
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *closingDB {
+			return &closingDB{closingResource: closingResource{name: "db", log: log}}
+		})
+
+		scope.Inject(func(db *closingDB) {})
+
+		if err := scope.Go(func(request wired.Scope) {
+			request.Register(func(db *closingDB) *closingTx {
+				return &closingTx{closingResource: closingResource{name: "tx", log: log}}
+			})
+			request.Inject(func(tx *closingTx) {})
+		}); err != nil {
+			t.Error("expected no errors, not", err)
+		}
+
+		if len(log.closed) != 1 || log.closed[0] != "tx" {
+			t.Error("expected only tx to be closed, not", log.closed)
+		}
+
+		if err := scope.Close(); err != nil {
+			t.Error("expected no errors, not", err)
+		}
+
+		if len(log.closed) != 2 || log.closed[1] != "db" {
+			t.Error("expected db to be closed, not", log.closed)
+		}
+
+		// closing twice should not close anything twice
+		//
+		scope.Close()
+		if len(log.closed) != 2 {
+			t.Error("expected nothing to be closed twice, not", log.closed)
+		}
+	})
+}
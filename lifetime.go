@@ -0,0 +1,124 @@
+package wired
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ScopedSingleton is a struct that can be mixed into another struct to express
+// exactly one object of this struct is constructed per scope it's resolved in.
+// Unlike singletons, objects constructed within a parent scope are not shared
+// with its sub scopes
+//
+type ScopedSingleton struct {
+}
+
+// Lifetime decides which scope holds the object of a type that is constructed
+// while resolving within a scope. Exactly one object is constructed per holding
+// scope, it's closed when this scope is closed
+//
+type Lifetime interface {
+
+	// Owner returns the scope, given scope or one of its parents, that holds the
+	// object constructed while resolving within given scope. An error should be
+	// returned when there is no such scope
+	//
+	Owner(scope Scope) (Scope, error)
+}
+
+type lifetimeTag struct {
+	lifetime Lifetime
+}
+
+type currentScope struct {
+}
+
+type namedScope struct {
+	name string
+}
+
+// lifetimeScope is implemented by scopes that are able to construct an object
+// exactly once within the scope a lifetime selected
+//
+type lifetimeScope interface {
+	owned(owner Scope, objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error)
+}
+
+func init() {
+	RegisterLifetime(reflect.TypeOf((*ScopedSingleton)(nil)).Elem(), &currentScope{})
+}
+
+// RegisterLifetime connects a struct tag to a lifetime. Objects of structs that
+// embed this tag are held by the scope selected by the lifetime
+//
+func RegisterLifetime(objType reflect.Type, lifetime Lifetime) {
+	RegisterConstructionTag(objType, &lifetimeTag{lifetime: lifetime})
+}
+
+// InScope returns a lifetime that holds objects within the nearest scope
+// that's created with given name, see GoNamed
+//
+func InScope(name string) Lifetime {
+	return &namedScope{name: name}
+}
+
+// Apply constructs an object once for the scope selected by the lifetime
+//
+func (lifetimeTag *lifetimeTag) Apply(scope Scope, objType reflect.Type, constructor func() interface{}) interface{} {
+	return tryApply(lifetimeTag, scope, objType, constructor)
+}
+
+// TryApply constructs an object once for the scope selected by the lifetime
+//
+func (lifetimeTag *lifetimeTag) TryApply(scope Scope, objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error) {
+
+	owner, err := lifetimeTag.lifetime.Owner(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if wire, ok := scope.(lifetimeScope); ok {
+		return wire.owned(owner, objType, constructor)
+	}
+
+	if object, found := owner.FindSingleton(objType); found {
+		return object, nil
+	}
+
+	constructed, err := constructor()
+	if err != nil {
+		return nil, err
+	}
+
+	owner.RegisterSingleton(objType, constructed)
+	return constructed, nil
+}
+
+func (lifetimeTag *lifetimeTag) ShouldAutoConstruct() bool {
+	return false
+}
+
+func (currentScope *currentScope) Owner(scope Scope) (Scope, error) {
+	return scope, nil
+}
+
+func (namedScope *namedScope) Owner(scope Scope) (Scope, error) {
+	for walk := scope; walk != nil; walk = walk.Parent() {
+		if walk.Name() == namedScope.name {
+			return walk, nil
+		}
+	}
+	return nil, fmt.Errorf("no scope named %q", namedScope.name)
+}
+
+// scopeOf returns the actual scope behind a scope that's handed out by wired
+//
+func scopeOf(wire Scope) (*scope, bool) {
+	switch wire := wire.(type) {
+	case *scope:
+		return wire, true
+	case *resolver:
+		return wire.scope, true
+	}
+	return nil, false
+}
@@ -0,0 +1,161 @@
+package wired_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type scopedStruct struct {
+	wired.ScopedSingleton
+
+	closed bool
+}
+
+func (scopedStruct *scopedStruct) Close() error {
+	scopedStruct.closed = true
+	return nil
+}
+
+func newScopedStruct() *scopedStruct {
+	return &scopedStruct{}
+}
+
+func TestScopedSingletonShouldBeConstructedOncePerScope(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newScopedStruct)
+
+		outer := wired.MustGet[*scopedStruct](scope)
+		if outer != wired.MustGet[*scopedStruct](scope) {
+			t.Error("expected one scoped singleton within a scope")
+		}
+
+		var inner *scopedStruct
+		scope.Go(func(sub wired.Scope) {
+			inner = wired.MustGet[*scopedStruct](sub)
+			if inner != wired.MustGet[*scopedStruct](sub) {
+				t.Error("expected one scoped singleton within a sub scope")
+			}
+		})
+
+		if inner == outer {
+			t.Error("expected sub scope not to use the scoped singleton of its parent")
+		}
+		if !inner.closed || outer.closed {
+			t.Error("expected scoped singleton to be closed with its scope")
+		}
+	})
+}
+
+type requestStruct struct {
+	wired.AutoWire
+
+	Scoped *scopedStruct
+}
+
+func TestInScopeShouldHoldObjectInNamedScope(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		wired.ProvideWithLifetime[*requestStruct](scope, wired.InScope("request"), func() *requestStruct {
+			return &requestStruct{}
+		})
+
+		if _, err := wired.Get[*requestStruct](scope); err == nil || !strings.Contains(err.Error(), `no scope named "request"`) {
+			t.Error("expected an error outside of a request scope, not", err)
+		}
+
+		var first, second *requestStruct
+		scope.GoNamed("request", func(request wired.Scope) {
+			if request.Name() != "request" || request.Parent() == nil {
+				t.Error("expected a named sub scope")
+			}
+
+			first = wired.MustGet[*requestStruct](request)
+			request.Go(func(inner wired.Scope) {
+				if wired.MustGet[*requestStruct](inner) != first {
+					t.Error("expected scopes within a request to share its object")
+				}
+			})
+		})
+		scope.GoNamed("request", func(request wired.Scope) {
+			second = wired.MustGet[*requestStruct](request)
+		})
+
+		if first == second {
+			t.Error("expected one object per request")
+		}
+	})
+}
+
+type perTenant struct{}
+
+type tenantLifetime struct{}
+
+func (tenantLifetime *tenantLifetime) Owner(scope wired.Scope) (wired.Scope, error) {
+	for walk := scope; walk != nil; walk = walk.Parent() {
+		if strings.HasPrefix(walk.Name(), "tenant:") {
+			return walk, nil
+		}
+	}
+	return scope, nil
+}
+
+type tenantConnection struct {
+	perTenant
+
+	tenant string
+}
+
+func TestCustomLifetime(t *testing.T) {
+	wired.RegisterLifetime(reflect.TypeOf(perTenant{}), &tenantLifetime{})
+
+	wired.GoNamed("tenant:acme", func(scope wired.Scope) {
+		constructions := 0
+		scope.Register(func() *tenantConnection {
+			constructions++
+			return &tenantConnection{tenant: scope.Name()}
+		})
+
+		for walk := 0; walk < 3; walk++ {
+			scope.Go(func(request wired.Scope) {
+				if connection := wired.MustGet[*tenantConnection](request); connection.tenant != "tenant:acme" {
+					t.Error("expected a connection of tenant acme, not", connection.tenant)
+				}
+			})
+		}
+
+		if constructions != 1 {
+			t.Error("expected one connection per tenant, not", constructions)
+		}
+	})
+}
+
+type requestSession struct {
+	dependency *singletonDependency
+}
+
+func TestInScopeShouldConstructWithBindingsOfNamedScope(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		wired.ProvideWithLifetime[*requestSession](scope, wired.InScope("request"), func(dependency *singletonDependency) *requestSession {
+			return &requestSession{dependency: dependency}
+		})
+
+		scope.GoNamed("request", func(request wired.Scope) {
+			request.Register(func() *singletonDependency { return &singletonDependency{name: "real"} })
+
+			var session *requestSession
+			request.Go(func(test wired.Scope) {
+				test.Register(func() *singletonDependency { return &singletonDependency{name: "mock"} })
+
+				if session = wired.MustGet[*requestSession](test); session.dependency.name != "real" {
+					t.Error("expected mock not to leak into an object held by the request scope")
+				}
+			})
+
+			if wired.MustGet[*requestSession](request) != session {
+				t.Error("expected request scope to hold the session")
+			}
+		})
+	})
+}
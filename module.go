@@ -0,0 +1,238 @@
+package wired
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Module groups constructors that belong together, so they can be installed
+// in a scope at once instead of being registered one by one
+//
+type Module struct {
+
+	// Name identifies a module in errors and graphs
+	//
+	Name string
+
+	// Constructors are registered when the module is installed
+	//
+	Constructors []interface{}
+
+	// Contributions are registered like constructors, but construct objects that
+	// are combined with those of other modules in slices and maps. Modules may
+	// contribute the same type without binding it twice
+	//
+	Contributions []interface{}
+
+	// Modules are installed before the constructors of this module are registered
+	//
+	Modules []*Module
+
+	// Config holds default values of configuration keys used by AutoConfig. Defaults
+	// are only used when no other configurator knows a key
+	//
+	Config map[string]string
+}
+
+// DuplicateBindingError tells two modules installed in the same scope register
+// a constructor for the same type and name
+//
+type DuplicateBindingError struct {
+	Type    reflect.Type
+	Name    string
+	Modules []string
+}
+
+func (err *DuplicateBindingError) Error() string {
+	if err.Name == "" {
+		return fmt.Sprintf("%v is bound by modules %s", err.Type, strings.Join(err.Modules, " and "))
+	}
+	return fmt.Sprintf("%v named %s is bound by modules %s", err.Type, err.Name, strings.Join(err.Modules, " and "))
+}
+
+// Install installs modules and the modules they contain. Installing a module
+// that's already installed in this scope or one of its parents does nothing.
+// When modules bind the same type, the constructor of the last installed module
+// is used and a DuplicateBindingError is returned for every such type.
+// Contributions of modules never conflict
+//
+func (scope *scope) Install(modules ...*Module) error {
+	errs := make(Errors, 0)
+
+	for _, module := range modules {
+		errs = append(errs, scope.install(module, make(map[*Module]bool))...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// installation tracks the installation of a module in a scope
+//
+type installation struct {
+	done      chan struct{} // closed when the installation finished
+	installed bool          // set when all constructors of the module are registered
+}
+
+// install installs a module unless it's installed already. Concurrent installs
+// of the same module wait for the first one to finish. Modules that are part of
+// the installation of the calling goroutine are skipped, so modules may contain
+// each other
+//
+func (scope *scope) install(module *Module, installing map[*Module]bool) Errors {
+	if module.Name == "" {
+		panic("module has no name")
+	}
+
+	if installing[module] {
+		return nil
+	}
+
+	if scope.parent != nil && scope.parent.installed(module) {
+		return nil
+	}
+
+	scope.lock.Lock()
+	for {
+		pending, found := scope.modules[module]
+		if !found {
+			break
+		}
+		scope.lock.Unlock()
+
+		// an installation that failed is forgotten, so it's tried again
+		//
+		<-pending.done
+		if pending.installed {
+			return nil
+		}
+		scope.lock.Lock()
+	}
+	if scope.modules == nil {
+		scope.modules = make(map[*Module]*installation)
+		scope.moduleBindings = make(map[binding]*Module)
+		scope.moduleContributions = make(map[binding]*Module)
+	}
+	pending := &installation{done: make(chan struct{})}
+	scope.modules[module] = pending
+	scope.lock.Unlock()
+
+	installed := false
+	defer func() {
+		scope.lock.Lock()
+		if installed {
+			pending.installed = true
+		} else {
+			delete(scope.modules, module)
+		}
+		scope.lock.Unlock()
+		close(pending.done)
+	}()
+
+	installing[module] = true
+
+	errs := make(Errors, 0)
+	for _, nested := range module.Modules {
+		errs = append(errs, scope.install(nested, installing)...)
+	}
+
+	for _, constructor := range module.Constructors {
+		key := module.bindingOf(constructor)
+
+		scope.lock.Lock()
+		if bound, found := scope.moduleBindings[key]; found && bound != module {
+			errs = append(errs, &DuplicateBindingError{Type: key.objType, Name: key.name, Modules: []string{bound.Name, module.Name}})
+		}
+		scope.moduleBindings[key] = module
+		scope.lock.Unlock()
+
+		scope.Register(constructor)
+	}
+
+	for _, contribution := range module.Contributions {
+		key := module.bindingOf(contribution)
+
+		scope.lock.Lock()
+		scope.moduleContributions[key] = module
+		scope.lock.Unlock()
+
+		scope.Register(contribution)
+	}
+
+	if len(module.Config) > 0 {
+		scope.lock.Lock()
+		scope.defaults = append(scope.defaults, &configDefaults{values: module.Config})
+		scope.lock.Unlock()
+	}
+
+	installed = true
+	return errs
+}
+
+// bindingOf returns the binding of the type constructed by a constructor of this module
+//
+func (module *Module) bindingOf(constructor interface{}) binding {
+	constructorType, constructs := constructedType(ensureConstructorIsAFunction(constructor))
+	if !constructs {
+		panic(fmt.Sprintf("constructor of module %s does not construct anything", module.Name))
+	}
+	return binding{objType: constructorType}
+}
+
+// installed tells whether a module is installed in this scope or one of its
+// parents, waiting for installations that did not finish yet
+//
+func (scope *scope) installed(module *Module) bool {
+	for walk := scope; walk != nil; walk = walk.parent {
+		walk.lock.RLock()
+		pending, found := walk.modules[module]
+		walk.lock.RUnlock()
+
+		if !found {
+			continue
+		}
+
+		<-pending.done
+		if pending.installed {
+			return true
+		}
+	}
+	return false
+}
+
+// configDefaults returns the default configuration values of all modules
+// installed in this scope and its parents, parents first
+//
+func (scope *scope) configDefaults() []Configurator {
+	var defaults []Configurator
+	if scope.parent != nil {
+		defaults = scope.parent.configDefaults()
+	}
+
+	scope.lock.RLock()
+	defer scope.lock.RUnlock()
+
+	for _, config := range scope.defaults {
+		defaults = append(defaults, config)
+	}
+	return defaults
+}
+
+// moduleOf returns the name of the module that registered the constructor of
+// given type and name in this scope, or an empty string
+//
+func (scope *scope) moduleOf(key binding) string {
+	scope.lock.RLock()
+	defer scope.lock.RUnlock()
+
+	if module, found := scope.moduleBindings[key]; found {
+		return module.Name
+	}
+	if module, found := scope.moduleContributions[key]; found {
+		return module.Name
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package wired_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type moduleStorage struct {
+	wired.AutoConfig
+
+	Path string `autoconfig:"${storage.path}"`
+}
+
+func newModuleStorage() *moduleStorage {
+	return &moduleStorage{}
+}
+
+type moduleService struct {
+	storage *moduleStorage
+}
+
+var storageModule = &wired.Module{
+	Name:         "storage",
+	Constructors: []interface{}{newModuleStorage},
+	Config:       map[string]string{"storage.path": "/var/lib/wired"}}
+
+var serviceModule = &wired.Module{
+	Name: "service",
+	Constructors: []interface{}{func(storage *moduleStorage) *moduleService {
+		return &moduleService{storage: storage}
+	}},
+	Modules: []*wired.Module{storageModule}}
+
+func TestInstallModule(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		if err := scope.Install(serviceModule); err != nil {
+			t.Fatal("expected no errors, not", err)
+		}
+
+		service := wired.MustGet[*moduleService](scope)
+		if service.storage == nil || service.storage.Path != "/var/lib/wired" {
+			t.Error("expected service to use storage configured by module defaults, not", service.storage)
+		}
+	})
+}
+
+func TestModuleConfigShouldNotOverrideOtherConfigurators(t *testing.T) {
+	t.Setenv("STORAGE_PATH", "/tmp")
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(wired.EnvironmentModule, storageModule)
+
+		if storage := wired.MustGet[*moduleStorage](scope); storage.Path != "/tmp" {
+			t.Error("expected environment to override module defaults, not", storage.Path)
+		}
+	})
+}
+
+func TestInstallShouldBeIdempotent(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		if err := scope.Install(storageModule, serviceModule, storageModule); err != nil {
+			t.Fatal("expected installing a module twice to be fine, not", err)
+		}
+
+		scope.Go(func(sub wired.Scope) {
+			if err := sub.Install(storageModule); err != nil {
+				t.Error("expected installing a module of a parent scope to be fine, not", err)
+			}
+		})
+	})
+}
+
+func TestConcurrentInstallShouldInstallOnce(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		var group sync.WaitGroup
+		for walk := 0; walk < 10; walk++ {
+			group.Add(1)
+			go func() {
+				defer group.Done()
+				scope.Install(serviceModule)
+
+				if _, err := wired.Get[*moduleService](scope); err != nil {
+					t.Error("expected module to be installed when install returns, not", err)
+				}
+			}()
+		}
+		group.Wait()
+
+		if storages := wired.MustGet[[]*moduleStorage](scope); len(storages) != 1 {
+			t.Error("expected module to be installed once, not", len(storages), "times")
+		}
+	})
+}
+
+func TestFailedInstallShouldNotMarkModuleInstalled(t *testing.T) {
+	broken := &wired.Module{
+		Name:         "broken",
+		Constructors: []interface{}{newModuleStorage, "not a constructor"}}
+
+	install := func(scope wired.Scope) (panicked bool) {
+		defer func() {
+			panicked = recover() != nil
+		}()
+		scope.Install(broken)
+		return
+	}
+
+	wired.Go(func(scope wired.Scope) {
+		if !install(scope) {
+			t.Fatal("expected install of a broken module to panic")
+		}
+		if !install(scope) {
+			t.Error("expected a broken module to be installed again, not to be marked installed")
+		}
+	})
+}
+
+func TestModulesMayContainEachOther(t *testing.T) {
+	client := &wired.Module{Name: "client", Constructors: []interface{}{newModuleStorage}}
+	server := &wired.Module{Name: "server", Modules: []*wired.Module{client}}
+	client.Modules = []*wired.Module{server}
+
+	wired.Go(func(scope wired.Scope) {
+		if err := scope.Install(server); err != nil {
+			t.Error("expected modules containing each other to be installed, not", err)
+		}
+		if _, err := wired.Get[*moduleStorage](scope); err != nil {
+			t.Error("expected constructors of contained modules to be registered, not", err)
+		}
+	})
+}
+
+func TestModuleConfigShouldNotBeInjectedAsConfigurator(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(storageModule)
+
+		if configs, err := wired.Get[map[string]wired.Configurator](scope); err == nil && len(configs) != 0 {
+			t.Error("expected module defaults not to be a named configurator, not", configs)
+		}
+		if _, err := wired.GetNamed[wired.Configurator](scope, "storage"); err == nil {
+			t.Error("expected name of module to be available")
+		}
+	})
+}
+
+func TestInstallShouldReportDuplicateBindings(t *testing.T) {
+	other := &wired.Module{Name: "other", Constructors: []interface{}{newModuleStorage}}
+
+	wired.Go(func(scope wired.Scope) {
+		err := scope.Install(storageModule, other)
+
+		var duplicate *wired.DuplicateBindingError
+		if !errors.As(err, &duplicate) {
+			t.Fatal("expected a duplicate binding, not", err)
+		}
+		if duplicate.Type != wired.TypeOf[*moduleStorage]() || !strings.Contains(err.Error(), "storage and other") {
+			t.Error("expected storage to be bound twice, not", err)
+		}
+	})
+}
+
+func TestModulesMayBothRegisterConfigurators(t *testing.T) {
+	flags := &wired.Module{
+		Name:          "flags",
+		Contributions: []interface{}{func() wired.Configurator { return &moduleFlags{} }}}
+
+	wired.Go(func(scope wired.Scope) {
+		if err := scope.Install(wired.EnvironmentModule, flags); err != nil {
+			t.Error("expected configurators of modules to be combined, not", err)
+		}
+
+		if configs := wired.MustGet[[]wired.Configurator](scope); len(configs) != 2 {
+			t.Error("expected both configurators, not", configs)
+		}
+	})
+}
+
+type moduleMiddleware struct {
+	name string
+}
+
+func TestModulesMayContributeTheSameType(t *testing.T) {
+	logging := &wired.Module{
+		Name:          "logging",
+		Contributions: []interface{}{func() *moduleMiddleware { return &moduleMiddleware{name: "logging"} }}}
+	metrics := &wired.Module{
+		Name:          "metrics",
+		Contributions: []interface{}{func() *moduleMiddleware { return &moduleMiddleware{name: "metrics"} }}}
+
+	wired.Go(func(scope wired.Scope) {
+		if err := scope.Install(logging, metrics); err != nil {
+			t.Error("expected contributions of modules not to conflict, not", err)
+		}
+
+		middleware := wired.MustGet[[]*moduleMiddleware](scope)
+		if len(middleware) != 2 || middleware[0].name != "logging" || middleware[1].name != "metrics" {
+			t.Error("expected middleware of both modules, not", middleware)
+		}
+	})
+}
+
+func TestModulesShouldNotRegisterTheSameConstructedType(t *testing.T) {
+	logging := &wired.Module{
+		Name:         "logging",
+		Constructors: []interface{}{func() *moduleMiddleware { return &moduleMiddleware{name: "logging"} }}}
+	metrics := &wired.Module{
+		Name:         "metrics",
+		Constructors: []interface{}{func() *moduleMiddleware { return &moduleMiddleware{name: "metrics"} }}}
+
+	wired.Go(func(scope wired.Scope) {
+		var duplicate *wired.DuplicateBindingError
+		if err := scope.Install(logging, metrics); !errors.As(err, &duplicate) {
+			t.Error("expected constructors of modules to conflict, not", err)
+		}
+	})
+}
+
+type moduleFlags struct {
+}
+
+func (moduleFlags *moduleFlags) ConfigValue(key string) string {
+	return ""
+}
+
+func TestModulesShouldBeGroupedInGraph(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Install(serviceModule)
+
+		graph := scope.Graph()
+		for _, node := range graph.Nodes {
+			if node.Type == wired.TypeOf[*moduleStorage]() && node.Module != "storage" {
+				t.Error("expected storage to be part of the storage module, not", node.Module)
+			}
+			if node.Type == wired.TypeOf[*moduleService]() && node.Module != "service" {
+				t.Error("expected service to be part of the service module, not", node.Module)
+			}
+		}
+
+		if dot := graph.DOT(); !strings.Contains(dot, `label="storage"`) {
+			t.Error("expected a cluster of the storage module, not", dot)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package wired
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// constructorPlan holds everything wired needs to know to call a constructor
+// function, so reflection is used only once per type of function
+//
+type constructorPlan struct {
+	in           []reflect.Type
+	outType      reflect.Type
+	constructs   bool
+	returnsError bool
+	tag          ConstructionTag // nil when the constructed type has no construction tag
+}
+
+// decorationPlan holds the fields of a struct that can be decorated, along with
+// the struct decoration tags that decorate them
+//
+type decorationPlan struct {
+	decorators []StructDecorationTag
+	fields     []reflect.StructField
+}
+
+var constructorPlans sync.Map // maps function types to *constructorPlan
+var decorationPlans sync.Map  // maps struct types to *decorationPlan
+
+// lookup is a cached result of finding the constructor of a binding
+//
+type lookup struct {
+	registrations uint64
+	constructor   interface{}
+	registrar     *scope // scope the constructor is registered in
+	found         bool
+}
+
+// settings holds the settings a scope shares with the scopes it creates, combined
+// with those of its parents. They're cached until a scope or one of its parents
+// changes
+//
+type settings struct {
+	registrations       uint64
+	tracer              Tracer
+	strict              bool
+	bindImplementations bool
+	decorators          map[reflect.Type][]interface{} // decorators in order of application
+}
+
+// settings returns the combined settings of this scope and its parents
+//
+func (scope *scope) settings() *settings {
+	current := scope.registered()
+
+	scope.lock.RLock()
+	cached := scope.inherited
+	scope.lock.RUnlock()
+
+	if cached != nil && cached.registrations == current {
+		return cached
+	}
+
+	combined := &settings{registrations: current}
+	if scope.parent != nil {
+		*combined = *scope.parent.settings()
+		combined.registrations = current
+	}
+
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	if scope.tracer != nil {
+		combined.tracer = scope.tracer
+	}
+	combined.strict = combined.strict || scope.strict
+	combined.bindImplementations = combined.bindImplementations || scope.bindImplementations
+
+	// decorators of parents are shared as long as this scope has none
+	//
+	if len(scope.decorators) > 0 {
+		decorators := make(map[reflect.Type][]interface{}, len(combined.decorators)+len(scope.decorators))
+		for objType, inherited := range combined.decorators {
+			decorators[objType] = inherited
+		}
+		for objType, own := range scope.decorators {
+			decorators[objType] = append(append([]interface{}{}, decorators[objType]...), own...)
+		}
+		combined.decorators = decorators
+	}
+
+	scope.inherited = combined
+	return combined
+}
+
+// forgetPlans drops all cached plans, since registering a tag changes
+// how types are constructed and decorated
+//
+func forgetPlans() {
+	for _, plans := range []*sync.Map{&constructorPlans, &decorationPlans} {
+		plans.Range(func(key, value interface{}) bool {
+			plans.Delete(key)
+			return true
+		})
+	}
+}
+
+// forgetLookups outdates all cached lookups of constructors and settings of this
+// scope and the scopes created by it. It must be called after a constructor is
+// registered or a setting is changed
+//
+func (scope *scope) forgetLookups() {
+	atomic.AddUint64(&scope.registrations, 1)
+}
+
+// registered returns the number of registrations of this scope and its parents,
+// which changes whenever a constructor is registered in any of them
+//
+func (scope *scope) registered() uint64 {
+	var registrations uint64
+	for walk := scope; walk != nil; walk = walk.parent {
+		registrations += atomic.LoadUint64(&walk.registrations)
+	}
+	return registrations
+}
+
+func planConstructor(constructor interface{}) (*constructorPlan, error) {
+	constructorType, err := functionType(constructor)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan, found := constructorPlans.Load(constructorType); found {
+		return plan.(*constructorPlan), nil
+	}
+
+	plan := &constructorPlan{
+		in:           make([]reflect.Type, constructorType.NumIn()),
+		returnsError: returnsError(constructorType)}
+
+	for walk := range plan.in {
+		plan.in[walk] = constructorType.In(walk)
+	}
+
+	plan.outType, plan.constructs = constructedType(constructorType)
+	if plan.constructs {
+		plan.tag, _ = FindConstructionTag(plan.outType)
+	}
+
+	constructorPlans.Store(constructorType, plan)
+	return plan, nil
+}
+
+// constructionTag returns the construction tag used by given constructor, which
+// is either the tag of a tagged constructor or the tag of the constructed type
+//
+func (plan *constructorPlan) constructionTag(constructor interface{}) (ConstructionTag, bool) {
+	if tagged, ok := constructor.(*tagged); ok {
+		return tagged.tag, true
+	}
+	return plan.tag, plan.tag != nil
+}
+
+func planDecoration(structType reflect.Type) *decorationPlan {
+	if plan, found := decorationPlans.Load(structType); found {
+		return plan.(*decorationPlan)
+	}
+
+	plan := &decorationPlan{decorators: FindStructDecorationTags(structType)}
+
+	if len(plan.decorators) > 0 {
+		for walk := 0; walk < structType.NumField(); walk++ {
+			field := structType.Field(walk)
+
+			// tags themselves are never decorated
+			//
+			if _, found := structDecorationTags[field.Type]; found {
+				continue
+			}
+			if _, found := constructionTags[field.Type]; found {
+				continue
+			}
+
+			plan.fields = append(plan.fields, field)
+		}
+	}
+
+	decorationPlans.Store(structType, plan)
+	return plan
+}
+
+// findConstructor looks up the constructor of a type and name in this scope and
+// its parents
+//
+func (scope *scope) findConstructor(objType reflect.Type, name string) (interface{}, bool) {
+	constructor, _, found := scope.findRegistration(objType, name)
+	return constructor, found
+}
+
+// findRegistration looks up the constructor of a type and name, along with the
+// scope it's registered in. Results are cached until another constructor is
+// registered
+//
+func (scope *scope) findRegistration(objType reflect.Type, name string) (interface{}, *scope, bool) {
+	key := binding{objType: objType, name: name}
+	current := scope.registered()
+
+	scope.lock.RLock()
+	cached, found := scope.lookups[key]
+	scope.lock.RUnlock()
+
+	if found && cached.registrations == current {
+		return cached.constructor, cached.registrar, cached.found
+	}
+
+	constructor, registrar, found := scope.searchConstructor(objType, name)
+
+	scope.lock.Lock()
+	if scope.lookups == nil {
+		scope.lookups = make(map[binding]lookup)
+	}
+	scope.lookups[key] = lookup{registrations: current, constructor: constructor, registrar: registrar, found: found}
+	scope.lock.Unlock()
+
+	return constructor, registrar, found
+}
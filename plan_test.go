@@ -0,0 +1,129 @@
+package wired_test
+
+import (
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type benchmarkConfig struct {
+}
+
+type benchmarkRepository struct {
+	wired.AutoWire
+
+	Config *benchmarkConfig
+}
+
+type benchmarkService struct {
+	wired.AutoWire
+
+	Repository *benchmarkRepository
+	Config     *benchmarkConfig
+	Empty      *emptyStruct
+}
+
+type benchmarkHandler struct {
+	service *benchmarkService
+}
+
+type benchmarkSingleton struct {
+	wired.Singleton
+}
+
+func TestCachedLookupShouldBeDroppedWhenParentRegisters(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Go(func(parent wired.Scope) {
+			parent.Go(func(child wired.Scope) {
+				if _, err := wired.Get[*singletonDependency](child); err == nil {
+					t.Fatal("expected dependency to be unknown")
+				}
+
+				scope.Register(func() *singletonDependency { return &singletonDependency{name: "scope"} })
+
+				if dependency, err := wired.Get[*singletonDependency](child); err != nil || dependency.name != "scope" {
+					t.Fatal("expected dependency registered in grand parent to be found, not", dependency, err)
+				}
+
+				parent.Register(func() *singletonDependency { return &singletonDependency{name: "parent"} })
+
+				if dependency := wired.MustGet[*singletonDependency](child); dependency.name != "parent" {
+					t.Error("expected dependency registered in parent to be used, not", dependency.name)
+				}
+			})
+		})
+	})
+}
+
+func TestCachedSettingsShouldBeDroppedWhenParentChanges(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newGreeter)
+
+		scope.Go(func(child wired.Scope) {
+			if greeting := wired.MustGet[greeter](child).Greet(); greeting != "hello" {
+				t.Fatal("expected greeter not to be decorated, not", greeting)
+			}
+
+			scope.Decorate(func(next greeter) greeter {
+				return &shoutingGreeter{next: next}
+			})
+
+			if greeting := wired.MustGet[greeter](child).Greet(); greeting != "hello!" {
+				t.Error("expected decorator registered in parent to be applied, not", greeting)
+			}
+		})
+	})
+}
+
+func registerBenchmark(scope wired.Scope) {
+	scope.Register(func() *benchmarkConfig { return &benchmarkConfig{} })
+	scope.Register(func() *benchmarkRepository { return &benchmarkRepository{} })
+	scope.Register(func() *benchmarkService { return &benchmarkService{} })
+	scope.Register(newEmptyStruct)
+	scope.Register(func() *benchmarkSingleton { return &benchmarkSingleton{} })
+}
+
+func newBenchmarkHandler(service *benchmarkService, singleton *benchmarkSingleton) *benchmarkHandler {
+	return &benchmarkHandler{service: service}
+}
+
+func BenchmarkInject(b *testing.B) {
+	wired.Go(func(scope wired.Scope) {
+		registerBenchmark(scope)
+		scope.Register(newBenchmarkHandler)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for walk := 0; walk < b.N; walk++ {
+			scope.Inject(func(handler *benchmarkHandler) {})
+		}
+	})
+}
+
+func BenchmarkInjectInRequestScope(b *testing.B) {
+	wired.Go(func(scope wired.Scope) {
+		registerBenchmark(scope)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for walk := 0; walk < b.N; walk++ {
+			scope.Go(func(request wired.Scope) {
+				request.Register(newBenchmarkHandler)
+				request.Inject(func(handler *benchmarkHandler) {})
+			})
+		}
+	})
+}
+
+func BenchmarkConstructByType(b *testing.B) {
+	wired.Go(func(scope wired.Scope) {
+		registerBenchmark(scope)
+		serviceType := wired.TypeOf[*benchmarkService]()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for walk := 0; walk < b.N; walk++ {
+			scope.ConstructByType(serviceType)
+		}
+	})
+}
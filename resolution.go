@@ -0,0 +1,453 @@
+package wired
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+var errNotAFunction = errors.New("constructor is not a function")
+
+// ResolutionError is returned when wired is not able to construct a type
+//
+type ResolutionError struct {
+
+	// Type that could not be constructed
+	//
+	Type reflect.Type
+
+	// Name of the constructor that was asked for, empty when the
+	// constructor registered without a name was asked for
+	//
+	Name string
+
+	// Path holds all types that were being resolved when resolution failed.
+	// It starts with the type that was requested first and ends with Type
+	//
+	Path []reflect.Type
+
+	// Constructor is the constructor function that requested Type. It is nil
+	// when Type was requested directly
+	//
+	Constructor interface{}
+
+	constructorName string
+
+	// Err holds the cause of the failure, like the error returned by a constructor.
+	// It is nil when wired simply does not know how to construct Type
+	//
+	Err error
+}
+
+func (err *ResolutionError) Error() string {
+	var message string
+	objType := fmt.Sprint(err.Type)
+	if err.Name != "" {
+		objType = fmt.Sprintf("%s named %s", objType, err.Name)
+	}
+
+	if err.Err == nil {
+		message = fmt.Sprintf("do not know how to construct %s", objType)
+	} else {
+		message = fmt.Sprintf("can not construct %s: %v", objType, err.Err)
+	}
+
+	if err.Constructor != nil {
+		message = fmt.Sprintf("%s, required by %s", message, err.constructorName)
+	}
+
+	if len(err.Path) > 1 {
+		path := make([]string, len(err.Path))
+		for i, objType := range err.Path {
+			path[i] = fmt.Sprint(objType)
+		}
+		message = fmt.Sprintf("%s (%s)", message, strings.Join(path, " -> "))
+	}
+
+	return message
+}
+
+// Unwrap returns the cause of the failure
+//
+func (err *ResolutionError) Unwrap() error {
+	return err.Err
+}
+
+// FieldError is returned when wired is not able to set a field of a struct
+//
+type FieldError struct {
+
+	// Struct is the type of struct that holds the field
+	//
+	Struct reflect.Type
+
+	// Field is the name of the field
+	//
+	Field string
+
+	// Err is the reason why the field could not be set
+	//
+	Err error
+}
+
+func (err *FieldError) Error() string {
+	return fmt.Sprintf("can not wire field %s of %v: %v", err.Field, err.Struct, err.Err)
+}
+
+// Unwrap returns the reason why the field could not be set
+//
+func (err *FieldError) Unwrap() error {
+	return err.Err
+}
+
+// Errors combines multiple errors into one
+//
+type Errors []error
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns all combined errors
+//
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// isUnknownType returns true when given error tells given type and name are not known
+// at all, in contrast to a type that is known but could not be constructed
+//
+func isUnknownType(err error, objType reflect.Type, name string) bool {
+	var resolutionErr *ResolutionError
+	return errors.As(err, &resolutionErr) && resolutionErr.Err == nil &&
+		resolutionErr.Type == objType && resolutionErr.Name == name
+}
+
+// requestedBy registers the constructor that requested a type that could not
+// be resolved. Only the first (innermost) constructor is remembered
+//
+func requestedBy(err error, constructor interface{}) error {
+	if resolutionErr, ok := err.(*ResolutionError); ok && resolutionErr.Constructor == nil {
+		resolutionErr.Constructor = function(constructor)
+		resolutionErr.constructorName = constructorName(constructor)
+	}
+	return err
+}
+
+// CycleError is the cause of a ResolutionError when types (indirectly)
+// depend on themselves
+//
+type CycleError struct {
+
+	// Cycle holds all types that depend on each other. It starts and ends
+	// with the same type
+	//
+	Cycle []reflect.Type
+
+	// Via describes for every type in Cycle, except the first one, what
+	// required it. Like a constructor argument or an AutoWire field
+	//
+	Via []string
+}
+
+func (err *CycleError) Error() string {
+	cycle := make([]string, len(err.Cycle))
+	via := make([]string, len(err.Via))
+	for i, objType := range err.Cycle {
+		cycle[i] = fmt.Sprint(objType)
+		if i > 0 {
+			via[i-1] = fmt.Sprintf("%v is %s", objType, err.Via[i-1])
+		}
+	}
+
+	return fmt.Sprintf("dependency cycle %s (%s)", strings.Join(cycle, " -> "), strings.Join(via, ", "))
+}
+
+// dependency describes why a type is needed. Because it's a constructor
+// argument, a field of a struct, an element of a slice or map or because
+// it implements an interface
+//
+type dependency struct {
+	constructor interface{}
+	owner       reflect.Type
+	field       string
+	implements  reflect.Type
+}
+
+func (dep dependency) String() string {
+	if dep.constructor != nil {
+		return fmt.Sprintf("argument of %s", constructorName(dep.constructor))
+	}
+	if dep.owner != nil && dep.field != "" {
+		return fmt.Sprintf("field %s of %v", dep.field, dep.owner)
+	}
+	if dep.owner != nil {
+		return fmt.Sprintf("element of %v", dep.owner)
+	}
+	if dep.implements != nil {
+		return fmt.Sprintf("implementation of %v", dep.implements)
+	}
+	return "requested"
+}
+
+// session holds everything that's shared by all steps of a single resolution
+//
+type session struct {
+	starts  []*start // starts of all objects constructed or found during the resolution
+	started int      // number of starts that were run
+}
+
+// add adds starts of objects that need to be started before they are handed over
+//
+func (session *session) add(starts []*start) {
+	for _, start := range starts {
+		if !session.contains(start) {
+			session.starts = append(session.starts, start)
+		}
+	}
+}
+
+func (session *session) contains(start *start) bool {
+	for _, known := range session.starts {
+		if known == start {
+			return true
+		}
+	}
+	return false
+}
+
+// start starts all objects that were constructed but not started yet
+//
+func (session *session) start() error {
+	starts := session.starts[session.started:]
+	session.started = len(session.starts)
+
+	for _, start := range starts {
+		if err := start.run(); err != nil {
+			objType := reflect.TypeOf(start.starter)
+			return &ResolutionError{Type: objType, Path: []reflect.Type{objType}, Err: err}
+		}
+	}
+	return nil
+}
+
+// resolution keeps track of all types that are in the process of being resolved.
+// Every step points to the step that needed its type. A resolution starts with
+// a root step without a type
+//
+type resolution struct {
+	session    *session
+	parent     *resolution
+	objType    reflect.Type
+	name       string
+	dependency dependency
+	ctx        context.Context // nil when not resolving for a context
+	building   int32           // not 0 while the object of this step is being built
+}
+
+func newResolution() *resolution {
+	return &resolution{session: &session{}}
+}
+
+func (res *resolution) root() bool {
+	return res.parent == nil
+}
+
+// build marks a step as being built until the returned function is called
+//
+func (res *resolution) build() func() {
+	atomic.AddInt32(&res.building, 1)
+	return func() {
+		atomic.AddInt32(&res.building, -1)
+	}
+}
+
+// isBuilding tells whether the object of this step is being built
+//
+func (res *resolution) isBuilding() bool {
+	return atomic.LoadInt32(&res.building) != 0
+}
+
+func (res *resolution) push(objType reflect.Type) *resolution {
+	return &resolution{session: res.session, parent: res, objType: objType, ctx: res.ctx}
+}
+
+// enter adds a type to the resolution that's in progress. When this type
+// is already being resolved, a dependency cycle is found
+//
+func (res *resolution) enter(objType reflect.Type, name string, dep dependency) (*resolution, error) {
+	entered := &resolution{session: res.session, parent: res, objType: objType, name: name, dependency: dep, ctx: res.ctx}
+
+	for walk := res; !walk.root(); walk = walk.parent {
+		if walk.objType == objType && walk.name == name {
+			return nil, entered.fail(objType, entered.cycle(walk))
+		}
+	}
+
+	return entered, nil
+}
+
+// cycle constructs a cycle error from the given step up to this step
+//
+func (res *resolution) cycle(from *resolution) error {
+	cycle := make([]reflect.Type, 0)
+	via := make([]string, 0)
+	for walk := res; walk != from; walk = walk.parent {
+		cycle = append([]reflect.Type{walk.objType}, cycle...)
+		via = append([]string{walk.dependency.String()}, via...)
+	}
+	return &CycleError{Cycle: append([]reflect.Type{from.objType}, cycle...), Via: via}
+}
+
+func (res *resolution) path() []reflect.Type {
+	path := make([]reflect.Type, 0)
+	for walk := res; !walk.root(); walk = walk.parent {
+		path = append([]reflect.Type{walk.objType}, path...)
+	}
+	return path
+}
+
+func (res *resolution) unknown() error {
+	return &ResolutionError{Type: res.objType, Name: res.name, Path: res.path()}
+}
+
+func (res *resolution) fail(objType reflect.Type, err error) error {
+	if res.objType != objType {
+		res = res.push(objType)
+	}
+	return &ResolutionError{Type: objType, Path: res.path(), Err: err}
+}
+
+// resolver is the Scope handed to construction and decoration tags. It
+// continues the resolution that's in progress instead of starting a new one
+//
+type resolver struct {
+	*scope
+	res        *resolution
+	dependency dependency
+
+	// build constructs the object that's being resolved within given scope,
+	// nil when not resolving for a construction tag
+	//
+	build func(owner *scope) (interface{}, error)
+}
+
+func (scope *scope) resolver(res *resolution, dep dependency) *resolver {
+	return &resolver{scope: scope, res: res, dependency: dep}
+}
+
+func (resolver *resolver) Inject(use interface{}) {
+	if err := resolver.TryInject(use); err != nil {
+		panic(err)
+	}
+}
+
+func (resolver *resolver) TryInject(use interface{}) error {
+	_, err := resolver.scope.construct(resolver.res, use)
+	return err
+}
+
+func (resolver *resolver) InjectNamed(use interface{}, names ...string) {
+	if err := resolver.TryInjectNamed(use, names...); err != nil {
+		panic(err)
+	}
+}
+
+func (resolver *resolver) TryInjectNamed(use interface{}, names ...string) error {
+	_, err := resolver.scope.construct(resolver.res, use, names...)
+	return err
+}
+
+func (resolver *resolver) InjectContext(ctx context.Context, use interface{}) {
+	if err := resolver.TryInjectContext(ctx, use); err != nil {
+		panic(err)
+	}
+}
+
+func (resolver *resolver) TryInjectContext(ctx context.Context, use interface{}) error {
+	_, err := resolver.scope.construct(resolver.res.withContext(ctx), use)
+	return err
+}
+
+func (resolver *resolver) Construct(use interface{}) interface{} {
+	constructed, err := resolver.TryConstruct(use)
+	if err != nil {
+		panic(err)
+	}
+	return constructed
+}
+
+func (resolver *resolver) TryConstruct(use interface{}) (interface{}, error) {
+	return resolver.scope.construct(resolver.res, use)
+}
+
+func (resolver *resolver) ConstructByType(objType reflect.Type) interface{} {
+	return resolver.ConstructNamed(objType, "")
+}
+
+func (resolver *resolver) TryConstructByType(objType reflect.Type) (interface{}, error) {
+	return resolver.TryConstructNamed(objType, "")
+}
+
+func (resolver *resolver) ConstructNamed(objType reflect.Type, name string) interface{} {
+	constructed, err := resolver.TryConstructNamed(objType, name)
+	if err != nil {
+		if isUnknownType(err, objType, name) {
+			return nil
+		}
+		panic(err)
+	}
+	return constructed
+}
+
+func (resolver *resolver) TryConstructNamed(objType reflect.Type, name string) (interface{}, error) {
+	return resolver.scope.constructByType(resolver.res, objType, name, resolver.dependency)
+}
+
+// singleton looks up or constructs a singleton for the constructor that's being resolved
+//
+func (resolver *resolver) singleton(objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error) {
+	return resolver.scope.singleton(resolver.res, binding{objType: objType, name: resolver.res.name}, constructor)
+}
+
+// owned looks up or constructs the object of the constructor that's being resolved
+// within given scope only. The object is constructed using the constructors of
+// this scope
+//
+func (resolver *resolver) owned(owner Scope, objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error) {
+	holder, ok := scopeOf(owner)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a scope constructed by wired", owner)
+	}
+
+	if holder == resolver.scope || resolver.build == nil {
+		return holder.singleton(resolver.res, binding{objType: objType, name: resolver.res.name}, constructor)
+	}
+
+	constructed, err := holder.singleton(resolver.res, binding{objType: objType, name: resolver.res.name}, func() (interface{}, error) {
+		return resolver.build(holder)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolver.scope.wrapBetween(resolver.res, holder, objType, constructed)
+}
+
+func (resolver *resolver) Name() string {
+	return resolver.scope.Name()
+}
+
+func (resolver *resolver) Parent() Scope {
+	return resolver.scope.Parent()
+}
+
+func (resolver *resolver) GoNamed(name string, f func(Scope)) error {
+	return resolver.scope.GoNamed(name, f)
+}
@@ -1,16 +1,50 @@
 package wired
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/okke/wired/internal"
 )
 
+// binding identifies a constructor by the type it constructs and
+// the name it has been registered with
+//
+type binding struct {
+	objType reflect.Type
+	name    string
+}
+
 type scope struct {
-	constructorMapping map[reflect.Type]interface{} // map type to constructor functions
-	singletons         map[reflect.Type]interface{} // map type to singleton objects
-	parent             *scope
+	lock                sync.RWMutex                   // guards all maps of a scope
+	constructorMapping  map[reflect.Type]interface{}   // map type to constructor functions
+	namedMapping        map[binding]interface{}        // map type and name to constructor functions
+	singletons          map[binding]interface{}        // map type and name to singleton objects
+	singletonLocks      map[binding]*sync.Mutex        // map type and name to lock used while constructing a singleton
+	starts              map[binding][]*start           // map type and name to starts of objects constructed with a singleton
+	lookups             map[binding]lookup             // cached lookups of constructors of this scope and its parents
+	registrations       uint64                         // number of registrations, outdates cached lookups
+	inherited           *settings                      // cached settings of this scope and its parents
+	elements            map[reflect.Type][]*element    // map slice and map types to the elements registered for them
+	excluded            map[reflect.Type][]interface{} // map type to names and keys of objects left out of slices and maps
+	excludedFunctions   map[string]bool                // functions of constructors left out of slices and maps
+	closables           []interface{}                  // singletons that can be closed in order of construction
+	decorators          map[reflect.Type][]interface{} // map type to decorators in order of registration
+	modules             map[*Module]*installation      // installed modules and those being installed
+	defaults            []*configDefaults              // default configuration values of installed modules
+	moduleBindings      map[binding]*Module            // map type and name to the module that registered its constructor
+	moduleContributions map[binding]*Module            // map type and name to the module that last contributed it
+	bindImplementations bool                           // bind interfaces to implementing types
+	strict              bool                           // require auto-wired fields to be wired
+	rebuildSingletons   bool                           // construct singletons of parent scopes again
+	tracer              Tracer                         // nil when not traced
+	name                string                         // empty when the scope is not named
+	parent              *scope
 }
 
 var scopeType = reflect.TypeOf((*scope)(nil))
@@ -19,23 +53,152 @@ var scopeType = reflect.TypeOf((*scope)(nil))
 //
 type Scope interface {
 
-	// Register a constructor function
+	// Register a constructor function. A constructor function returns the
+	// constructed object, optionally followed by an error
 	//
 	Register(constructor interface{})
 
+	// Register a constructor function under a given name. Objects constructed
+	// by a named constructor are only injected when they are asked for by name
+	// or as part of a slice or map
+	//
+	RegisterNamed(name string, constructor interface{})
+
+	// Register a constructor function when given condition holds. Conditions
+	// are evaluated immediately, see OnConfig, OnProfile and OnRegistered
+	//
+	RegisterIf(condition Condition, constructor interface{})
+
+	// Register a constructor function like Register does, with a priority that
+	// tells the position of the objects it constructs in slices. Objects with a
+	// lower priority come first, the default priority is 0. Objects that implement
+	// Orderer decide their priority themselves
+	//
+	RegisterWithPriority(priority int, constructor interface{})
+
+	// Leave the objects of a constructor out of all slices and maps constructed
+	// within this scope and the scopes it creates
+	//
+	Exclude(constructor interface{})
+
+	// Leave the objects of given type that are registered with given name or key
+	// out of all slices and maps constructed within this scope and the scopes it creates
+	//
+	ExcludeKey(objType reflect.Type, key interface{})
+
+	// Register a constructor function like Register does. In maps of the type it
+	// constructs, its objects are injected with given key
+	//
+	RegisterKeyed(key interface{}, constructor interface{})
+
+	// Register a constructor function like Register does. In maps of the type it
+	// constructs, its objects are injected with the key returned by given key
+	// function. A key function accepts a constructed object and returns its key
+	//
+	RegisterKeyedBy(keyFunction interface{}, constructor interface{})
+
+	// Register a constructor function for the type it constructs and
+	// for all given interfaces
+	//
+	RegisterAs(constructor interface{}, ifaceTypes ...reflect.Type)
+
+	// Install modules and all modules they contain. Modules that are already
+	// installed, in this scope or one of its parents, are skipped. When modules
+	// register constructors for the same type, an error is returned
+	//
+	Install(modules ...*Module) error
+
+	// Decorate all objects of a type constructed within this scope, or the
+	// scopes it creates. A decorator accepts the constructed object as its first
+	// argument and returns an object of the same type, like a wrapper. Other
+	// arguments are injected. Decorators are applied in order of registration
+	//
+	Decorate(decorator interface{})
+
+	// Bind interfaces that have no registered constructor to the constructor
+	// of a type that implements them
+	//
+	BindImplementations()
+
+	// Fail when exported auto-wired fields of objects constructed within this
+	// scope, or the scopes it creates, can not be wired
+	//
+	StrictAutoWire()
+
+	// Construct singletons whose constructors are registered in a parent scope
+	// again, within this scope and using the constructors of this scope, instead
+	// of sharing the singletons of the parent. Sub scopes share the singletons
+	// of this scope
+	//
+	RebuildSingletons()
+
+	// Trace resolution of objects within this scope and the scopes it creates
+	//
+	SetTracer(tracer Tracer)
+
 	// Construct an object by providing a constructor function. Wired will
 	// inject valid function arguments.
 	//
 	Construct(use interface{}) interface{}
 
+	// TryConstruct does the same as Construct but returns a *ResolutionError
+	// instead of panicking when the object can not be constructed
+	//
+	TryConstruct(use interface{}) (interface{}, error)
+
 	// Call a function and autowire function arguments
 	//
 	Inject(use interface{})
 
+	// TryInject does the same as Inject but returns a *ResolutionError
+	// instead of panicking when arguments can not be constructed. When the
+	// function itself returns an error, this error is returned
+	//
+	TryInject(use interface{}) error
+
+	// Call a function and autowire function arguments. Given names are used,
+	// in order of the function arguments, to select a named constructor. An
+	// empty name selects the constructor that was registered without a name
+	//
+	InjectNamed(use interface{}, names ...string)
+
+	// TryInjectNamed does the same as InjectNamed but returns an error
+	// instead of panicking
+	//
+	TryInjectNamed(use interface{}, names ...string) error
+
+	// Call a function and autowire function arguments. All constructors
+	// that have a context.Context argument will receive given context.
+	// Resolution stops when the context is done
+	//
+	InjectContext(ctx context.Context, use interface{})
+
+	// TryInjectContext does the same as InjectContext but returns an error
+	// instead of panicking. When the context is done, the returned error
+	// wraps the error of the context
+	//
+	TryInjectContext(ctx context.Context, use interface{}) error
+
 	// Construct an object by providing the type that needs to be created.
+	// Returns nil when wired does not know how to construct given type
 	//
 	ConstructByType(reflect.Type) interface{}
 
+	// TryConstructByType does the same as ConstructByType but returns a
+	// *ResolutionError when the type is unknown or can not be constructed
+	//
+	TryConstructByType(reflect.Type) (interface{}, error)
+
+	// Construct an object by providing its type and the name its constructor
+	// was registered with. Returns nil when no such constructor is known
+	//
+	ConstructNamed(objType reflect.Type, name string) interface{}
+
+	// TryConstructNamed does the same as ConstructNamed but returns a
+	// *ResolutionError when the object is unknown or can not be constructed
+	//
+	TryConstructNamed(objType reflect.Type, name string) (interface{}, error)
+
 	// Lookup a singleton by type and return it. When the singleton is found
 	// the returned bool will be true. Otherwise it will be false.
 	//
@@ -46,15 +209,50 @@ type Scope interface {
 	//
 	RegisterSingleton(objType reflect.Type, value interface{})
 
-	// Construct a sub scope and use it within given function
+	// Validate checks whether all registered constructors can be called and
+	// whether all auto-wired fields of the objects they construct can be set.
+	// No constructor is called while doing so. All problems found are returned
+	// as Errors, nil is returned when there are none
+	//
+	Validate() error
+
+	// Graph returns all types this scope and its parents know how to construct
+	// and how they depend on each other
 	//
-	Go(f func(Scope))
+	Graph() *Graph
+
+	// Close closes all singletons constructed within this scope that implement
+	// io.Closer or have a Close() method. Singletons are closed in reverse order
+	// of construction and forgotten afterwards. All errors returned while closing
+	// are combined in Errors
+	//
+	Close() error
+
+	// Construct a sub scope and use it within given function. The sub scope
+	// is closed afterwards and the error returned by its Close method is returned
+	//
+	Go(f func(Scope)) error
+
+	// Construct a named sub scope and use it within given function, just like Go
+	// does. Lifetimes can use the name to select the scope that holds an object
+	//
+	GoNamed(name string, f func(Scope)) error
+
+	// Name returns the name a scope is created with, or an empty string
+	//
+	Name() string
+
+	// Parent returns the scope that created this scope, nil when there is none
+	//
+	Parent() Scope
 }
 
-func newScope(parent *scope) Scope {
+func newScope(parent *scope) *scope {
 	return &scope{
 		constructorMapping: make(map[reflect.Type]interface{}, 0),
-		singletons:         make(map[reflect.Type]interface{}, 0),
+		namedMapping:       make(map[binding]interface{}, 0),
+		singletons:         make(map[binding]interface{}, 0),
+		singletonLocks:     make(map[binding]*sync.Mutex, 0),
 		parent:             parent}
 }
 
@@ -68,161 +266,802 @@ func Global() Scope {
 }
 
 // Go will create a new Scope and use the callback to do whatever
-// you like to do with the created scope
+// you like to do with the created scope. Afterwards the scope is closed
+//
+func Go(f func(Scope)) error {
+	return useAndClose(newScope(nil), f)
+}
+
+// GoNamed does the same as Go but creates a scope with the given name
 //
-func Go(f func(Scope)) {
-	f(newScope(nil))
+func GoNamed(name string, f func(Scope)) error {
+	created := newScope(nil)
+	created.name = name
+	return useAndClose(created, f)
+}
+
+func useAndClose(scope *scope, f func(Scope)) (err error) {
+	defer func() {
+		err = scope.Close()
+	}()
+
+	f(scope)
+	return nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// returnsError returns true when the last result of a function is an error
+//
+func returnsError(functionType reflect.Type) bool {
+	numOut := functionType.NumOut()
+	return numOut > 0 && functionType.Out(numOut-1) == errorType
+}
+
+// constructedType returns the type of object a function constructs. A function
+// can return this object optionally followed by an error. When a function does
+// not construct anything, false is returned
+//
+func constructedType(functionType reflect.Type) (reflect.Type, bool) {
+	if functionType.NumOut() == 0 || (functionType.NumOut() == 1 && returnsError(functionType)) {
+		return nil, false
+	}
+	return functionType.Out(0), true
+}
+
+// method is a constructor function that's bound to an object, like the Construct
+// methods of a factory. Since reflection can not tell the name of such a function,
+// method remembers it
+//
+type method struct {
+	receiver reflect.Type
+	name     string
+	function interface{}
+}
+
+// function returns the actual function of a constructor
+//
+func function(constructor interface{}) interface{} {
+	switch constructor := constructor.(type) {
+	case *method:
+		return constructor.function
+	case *tagged:
+		return function(constructor.constructor)
+	}
+	return constructor
+}
+
+// tagged is a constructor that uses a construction tag, no matter which
+// tags the type it constructs has
+//
+type tagged struct {
+	constructor interface{}
+	tag         ConstructionTag
+}
+
+// constructionTag returns the construction tag used by a constructor of given type
+//
+func constructionTag(constructor interface{}, objType reflect.Type) (ConstructionTag, bool) {
+	if tagged, ok := constructor.(*tagged); ok {
+		return tagged.tag, true
+	}
+	return FindConstructionTag(objType)
+}
+
+// methodValueCall is the function of all method values created by reflection
+//
+var methodValueCall = reflect.ValueOf(&sync.Mutex{}).MethodByName("Lock").Pointer()
+
+// functionOf identifies the function of a constructor. Closures created by the
+// same function literal share their function. An empty string is returned for
+// method values created by reflection, since those can not be told apart
+//
+func functionOf(constructor interface{}) string {
+	switch constructor := constructor.(type) {
+	case *method:
+		if method, found := constructor.receiver.MethodByName(constructor.name); found {
+			return internal.FunctionName(method.Func.Interface())
+		}
+		return ""
+	case *tagged:
+		return functionOf(constructor.constructor)
+	}
+
+	value := reflect.ValueOf(constructor)
+	if value.Kind() != reflect.Func || value.Pointer() == methodValueCall {
+		return ""
+	}
+	return internal.FunctionName(constructor)
+}
+
+// constructorName returns a human readable name of a constructor
+//
+func constructorName(constructor interface{}) string {
+	switch constructor := constructor.(type) {
+	case *method:
+		return fmt.Sprintf("(%v).%s", constructor.receiver, constructor.name)
+	case *tagged:
+		return constructorName(constructor.constructor)
+	}
+	return internal.FunctionName(constructor)
+}
+
+func functionType(constructor interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(function(constructor))
+
+	if t == nil || t.Kind() != reflect.Func {
+		return nil, errNotAFunction
+	}
+
+	return t, nil
 }
 
 func ensureConstructorIsAFunction(constructor interface{}) reflect.Type {
-	t := reflect.TypeOf(constructor)
+	t, err := functionType(constructor)
 
-	if t.Kind() != reflect.Func {
-		panic("constructor is not a function")
+	if err != nil {
+		panic(err.Error())
 	}
 
 	return t
 }
 
-func (scope *scope) Go(f func(Scope)) {
-	f(newScope(scope))
+func (scope *scope) Go(f func(Scope)) error {
+	return useAndClose(newScope(scope), f)
+}
+
+func (scope *scope) GoNamed(name string, f func(Scope)) error {
+	created := newScope(scope)
+	created.name = name
+	return useAndClose(created, f)
+}
+
+func (scope *scope) Name() string {
+	return scope.name
+}
+
+func (scope *scope) Parent() Scope {
+	if scope.parent == nil {
+		return nil
+	}
+	return scope.parent
+}
+
+func (scope *scope) Close() error {
+	scope.lock.Lock()
+	closables := scope.closables
+	scope.closables = nil
+	for key, singleton := range scope.singletons {
+		if _, found := closer(singleton); found {
+			delete(scope.singletons, key)
+		}
+	}
+	scope.lock.Unlock()
+
+	errs := make(Errors, 0)
+	for walk := len(closables) - 1; walk >= 0; walk-- {
+		closeSingleton, _ := closer(closables[walk])
+		if err := closeSingleton(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
 }
 
-func (scope *scope) findConstructor(objType reflect.Type) (interface{}, bool) {
-	result, found := scope.constructorMapping[objType]
-	if !found && scope.parent != nil {
-		result, found = scope.parent.findConstructor(objType)
+// searchConstructor looks up a constructor without using cached lookups
+//
+func (scope *scope) searchConstructor(objType reflect.Type, name string) (interface{}, *scope, bool) {
+	scope.lock.RLock()
+	result, found := scope.lookupConstructor(objType, name)
+	scope.lock.RUnlock()
+
+	if found {
+		return result, scope, true
 	}
+	if scope.parent != nil {
+		return scope.parent.findRegistration(objType, name)
+	}
+	return nil, nil, false
+}
+
+// lookupConstructor expects the scope to be locked
+//
+func (scope *scope) lookupConstructor(objType reflect.Type, name string) (interface{}, bool) {
+	if name == "" {
+		result, found := scope.constructorMapping[objType]
+		return result, found
+	}
+	result, found := scope.namedMapping[binding{objType: objType, name: name}]
 	return result, found
 }
 
+// canResolve tells whether wired knows how to construct a type, without
+// constructing it
+//
+func (scope *scope) canResolve(objType reflect.Type, name string) bool {
+	if name == "" && (objType == scopeType || objType == contextType || objType.Kind() == reflect.Slice) {
+		return true
+	}
+
+	if _, found := scope.findConstructor(objType, name); found {
+		return true
+	}
+
+	if provided, ok := providedType(objType); ok {
+		return scope.canResolve(provided, name)
+	}
+
+	bound, err := scope.findImplementation(objType, name)
+	return bound != nil || err != nil
+}
+
 func (scope *scope) FindSingleton(objType reflect.Type) (interface{}, bool) {
-	if value, found := scope.singletons[objType]; found {
+	return scope.findSingleton(binding{objType: objType})
+}
+
+func (scope *scope) findSingleton(key binding) (interface{}, bool) {
+	if value, found := scope.findOwnSingleton(key); found {
 		return value, true
 	}
 
 	if scope.parent != nil {
-		value, found := scope.parent.FindSingleton(objType)
-		return value, found
+		return scope.parent.findSingleton(key)
 	}
 
 	return nil, false
 }
 
+// findOwnSingleton looks up a singleton within this scope, ignoring its parents
+//
+func (scope *scope) findOwnSingleton(key binding) (interface{}, bool) {
+	scope.lock.RLock()
+	defer scope.lock.RUnlock()
+
+	value, found := scope.singletons[key]
+	return value, found
+}
+
 func (scope *scope) RegisterSingleton(objType reflect.Type, value interface{}) {
-	scope.singletons[objType] = value
+	scope.registerSingleton(binding{objType: objType}, value)
 }
 
-func (wire *scope) registerSliceConstructor(constructor interface{}, constructorType reflect.Type) {
+func (scope *scope) registerSingleton(key binding, value interface{}) {
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	scope.singletons[key] = value
+
+	// remember singletons that need to be closed when the scope is done
+	//
+	if _, found := closer(value); found {
+		scope.closables = append(scope.closables, value)
+	}
+}
+
+// singleton returns the singleton of given type and name held by this scope. When
+// it does not exist yet, it's constructed and registered. Construction is guaranteed
+// to happen only once, also when multiple go routines ask for the same singleton.
+// Objects constructed along with the singleton that were not started yet, are
+// started by given resolution
+//
+func (scope *scope) singleton(res *resolution, key binding, constructor func() (interface{}, error)) (interface{}, error) {
+
+	if object, found := scope.findOwnSingleton(key); found {
+		scope.trace(func() Event {
+			return Event{Kind: SingletonFoundEvent, Type: key.objType, Name: key.name}
+		})
+		res.session.add(scope.startsOf(key))
+		return object, nil
+	}
+
+	scope.lock.Lock()
+	singletonLock, found := scope.singletonLocks[key]
+	if !found {
+		singletonLock = &sync.Mutex{}
+		scope.singletonLocks[key] = singletonLock
+	}
+	scope.lock.Unlock()
+
+	singletonLock.Lock()
+	defer singletonLock.Unlock()
+
+	// singleton could have been constructed while waiting for the lock
+	//
+	if object, found := scope.findOwnSingleton(key); found {
+		res.session.add(scope.startsOf(key))
+		return object, nil
+	}
+
+	constructing := len(res.session.starts)
+	constructed, err := constructor()
+	if err != nil {
+		return nil, err
+	}
+
+	// starts are known before the singleton can be found
+	//
+	if starts := res.session.starts[constructing:]; len(starts) > 0 {
+		scope.lock.Lock()
+		if scope.starts == nil {
+			scope.starts = make(map[binding][]*start)
+		}
+		scope.starts[key] = append([]*start{}, starts...)
+		scope.lock.Unlock()
+	}
+
+	scope.registerSingleton(key, constructed)
+	return constructed, nil
+}
+
+// startsOf returns the starts of objects constructed along with a singleton
+//
+func (scope *scope) startsOf(key binding) []*start {
+	scope.lock.RLock()
+	defer scope.lock.RUnlock()
+
+	return scope.starts[key]
+}
+
+// aggregator constructs a value (like a slice or map) out of all elements
+// registered for it, within the resolving scope and its parents
+//
+type aggregator struct {
+	objType reflect.Type
+}
+
+// element is a constructor of one of the values of a slice or map
+//
+type element struct {
+	constructor interface{}
+	name        string // name the constructor was registered with
+	function    string // identifies the constructor function, see Exclude
+	priority    int
+	registrar   *scope // scope the constructor is registered in
+	sequence    uint64 // tells the order of registration
+
+	// key is the key of the element in a map when it's known upfront, and keyOf
+	// returns the key of a constructed element. These are nil when the element
+	// is not part of a map
+	//
+	key     interface{}
+	keyType reflect.Type
+	keyOf   func(constructed reflect.Value) reflect.Value
+}
+
+// keyByMethod returns the key of a constructed element using its Key method
+//
+func keyByMethod(constructed reflect.Value) reflect.Value {
+	return reflect.ValueOf(constructed.Interface()).MethodByName("Key").Call([]reflect.Value{})[0]
+}
+
+// DuplicateKeyError tells multiple objects that are injected in the same map
+// have the same key
+//
+type DuplicateKeyError struct {
+	Key          interface{}
+	Constructors []string
+}
+
+func (err *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %v, constructed by %s", err.Key, strings.Join(err.Constructors, " and "))
+}
+
+// Orderer can be implemented by objects that are injected as part of a slice
+// to tell their position. Objects with a lower order come first
+//
+type Orderer interface {
+	Order() int
+}
+
+var ordererType = reflect.TypeOf((*Orderer)(nil)).Elem()
+
+// sequence counts registrations of elements, so elements can be kept in
+// order of registration
+//
+var sequence uint64
+
+func (aggregator *aggregator) aggregate(activeScope *scope, res *resolution) (interface{}, error) {
+	candidates := activeScope.elementsOf(aggregator.objType)
+	excluded := activeScope.excludedOf(aggregator.objType.Elem())
+
+	elements := make([]*element, 0, len(candidates))
+	values := make([]reflect.Value, 0, len(candidates))
+	priorities := make([]int, 0, len(candidates))
+
+	for _, element := range candidates {
+		entered, err := res.enter(aggregator.objType.Elem(), element.name, dependency{owner: aggregator.objType})
+		if err != nil {
+			return nil, err
+		}
+
+		owner := activeScope
+		if isOwned(element.constructor) {
+			owner = activeScope.owner(element.registrar)
+		}
+
+		constructed, err := owner.invoke(entered, element.constructor)
+		if err == nil {
+			constructed, err = activeScope.wrapOwned(entered, owner, element.constructor, constructed)
+		}
+		if err != nil {
+			return nil, err
+		}
 
-	sliceType := reflect.SliceOf(constructorType)
+		value := reflect.New(aggregator.objType.Elem()).Elem()
+		if constructed != nil {
+			value.Set(reflect.ValueOf(constructed))
+		}
 
-	knownConstructor, found := wire.findConstructor(sliceType)
+		// keys that are not known upfront can only be excluded once constructed
+		//
+		if element.key == nil && element.keyOf != nil && containsKey(excluded, element.keyOf(value).Interface()) {
+			continue
+		}
 
-	wire.constructorMapping[sliceType] = func(activeScope *scope) interface{} {
-		if !found {
-			return internal.CreateSliceWithValues(
-				sliceType,
-				activeScope.Construct(constructor)).Interface()
+		priority := element.priority
+		if orderer, ok := constructed.(Orderer); ok {
+			priority = orderer.Order()
 		}
 
-		return internal.CreateSliceWithValues(
-			sliceType,
-			activeScope.Construct(constructor),
-			activeScope.Construct(knownConstructor)).Interface()
+		elements = append(elements, element)
+		values = append(values, value)
+		priorities = append(priorities, priority)
 	}
 
+	order := make([]int, len(elements))
+	for walk := range order {
+		order[walk] = walk
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return priorities[order[i]] < priorities[order[j]]
+	})
+
+	if aggregator.objType.Kind() == reflect.Map {
+		return activeScope.mapElements(res, aggregator.objType, elements, values)
+	}
+
+	slice := reflect.MakeSlice(aggregator.objType, 0, len(values))
+	for _, index := range order {
+		slice = reflect.Append(slice, values[index])
+	}
+	return slice.Interface(), nil
 }
 
-func (wire *scope) registerMapConstructor(constructor interface{}, constructorType reflect.Type) {
+// mapElements puts constructed elements in a map by their key. When elements have
+// the same key, the element registered in the scope nearest to this scope replaces
+// the others. Elements of the same scope with the same key are reported
+//
+func (wire *scope) mapElements(res *resolution, mapType reflect.Type, elements []*element, values []reflect.Value) (interface{}, error) {
+	depths := make(map[*scope]int)
+	depth := 0
+	for walk := wire; walk != nil; walk = walk.parent {
+		depths[walk] = depth
+		depth++
+	}
 
-	keyMethod, found := constructorType.MethodByName("Key")
-	if !found {
-		return
+	mapping := reflect.MakeMapWithSize(mapType, len(values))
+	mappedBy := make(map[interface{}]*element, len(values))
+
+	for index, value := range values {
+		key := elements[index].keyOf(value)
+
+		if known, found := mappedBy[key.Interface()]; found {
+			switch knownDepth, depth := depths[known.registrar], depths[elements[index].registrar]; {
+			case knownDepth < depth:
+				continue
+			case knownDepth == depth:
+				return nil, res.fail(mapType, &DuplicateKeyError{
+					Key:          key.Interface(),
+					Constructors: []string{constructorName(known.constructor), constructorName(elements[index].constructor)}})
+			}
+		}
+
+		mappedBy[key.Interface()] = elements[index]
+		mapping.SetMapIndex(key, value)
 	}
+	return mapping.Interface(), nil
+}
 
-	if keyMethod.Type.NumOut() == 0 {
-		return
+// elementsOf returns all elements of a slice or map type registered in this
+// scope and its parents, in order of registration. Elements excluded by their
+// constructor, name or key are left out
+//
+func (scope *scope) elementsOf(objType reflect.Type) []*element {
+	elements := make([]*element, 0)
+	excludedFunctions := make(map[string]bool)
+
+	for walk := scope; walk != nil; walk = walk.parent {
+		walk.lock.RLock()
+		elements = append(elements, walk.elements[objType]...)
+		for function := range walk.excludedFunctions {
+			excludedFunctions[function] = true
+		}
+		walk.lock.RUnlock()
 	}
 
-	keyType := keyMethod.Type.Out(0)
-	mapType := reflect.MapOf(keyType, constructorType)
+	excluded := scope.excludedOf(objType.Elem())
+
+	included := elements[:0]
+	for _, element := range elements {
+		switch {
+		case element.function != "" && excludedFunctions[element.function]:
+		case containsKey(excluded, element.name):
+		case element.key != nil && containsKey(excluded, element.key):
+		default:
+			included = append(included, element)
+		}
+	}
 
-	knownConstructor, found := wire.findConstructor(mapType)
+	sort.Slice(included, func(i, j int) bool {
+		return included[i].sequence < included[j].sequence
+	})
+	return included
+}
 
-	wire.constructorMapping[mapType] = func(activeScope *scope) interface{} {
-		constructed := activeScope.Construct(constructor)
-		constructedKey := reflect.ValueOf(constructed).MethodByName("Key").Call([]reflect.Value{})[0].Interface()
-		if !found {
-			return internal.CreateMapWithValues(
-				mapType,
-				constructedKey,
-				constructed).Interface()
+// excludedOf returns the names and keys of objects of given type that are left
+// out of slices and maps by this scope and its parents
+//
+func (scope *scope) excludedOf(objType reflect.Type) []interface{} {
+	var excluded []interface{}
+
+	for walk := scope; walk != nil; walk = walk.parent {
+		walk.lock.RLock()
+		excluded = append(excluded, walk.excluded[objType]...)
+		walk.lock.RUnlock()
+	}
+	return excluded
+}
+
+func containsKey(keys []interface{}, key interface{}) bool {
+	for _, known := range keys {
+		if reflect.TypeOf(known) == reflect.TypeOf(key) && known == key {
+			return true
 		}
+	}
+	return false
+}
+
+// Exclude leaves the objects of a constructor out of all slices and maps constructed
+// within this scope and the scopes it creates. Constructors are identified by their
+// function, so all closures created by the same function literal are excluded.
+// Factory methods are excluded using a method value or method expression
+//
+func (scope *scope) Exclude(constructor interface{}) {
+	ensureConstructorIsAFunction(constructor)
+
+	function := functionOf(constructor)
+	if function == "" {
+		panic(fmt.Sprintf("can not exclude %v, its function is unknown, exclude it by name or key instead", reflect.TypeOf(constructor)))
+	}
+
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	if scope.excludedFunctions == nil {
+		scope.excludedFunctions = make(map[string]bool)
+	}
+	scope.excludedFunctions[function] = true
+}
+
+// ExcludeKey leaves the objects of given type out of all slices and maps constructed
+// within this scope and the scopes it creates. Objects are identified by the name
+// or key they're registered with, or by the key returned by their Key method
+//
+func (scope *scope) ExcludeKey(objType reflect.Type, key interface{}) {
+	if key == nil || key == "" || !reflect.TypeOf(key).Comparable() {
+		panic(fmt.Sprintf("can not exclude %v by %v, a name or key is required", objType, key))
+	}
+
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	if scope.excluded == nil {
+		scope.excluded = make(map[reflect.Type][]interface{})
+	}
+	scope.excluded[objType] = append(scope.excluded[objType], key)
+}
+
+// registerAggregator expects the scope to be locked
+//
+func (wire *scope) registerAggregator(entry *element, objType reflect.Type) {
+	if _, found := wire.constructorMapping[objType]; !found {
+		wire.constructorMapping[objType] = &aggregator{objType: objType}
+	}
 
-		return internal.CreateMapWithValues(
-			mapType,
-			constructedKey,
-			constructed,
-			activeScope.Construct(knownConstructor)).Interface()
+	if entry.sequence == 0 {
+		entry.sequence = atomic.AddUint64(&sequence, 1)
+	}
+	if entry.function == "" {
+		entry.function = functionOf(entry.constructor)
+	}
+	entry.registrar = wire
+
+	if wire.elements == nil {
+		wire.elements = make(map[reflect.Type][]*element)
+	}
+	wire.elements[objType] = append(wire.elements[objType], entry)
+}
+
+func (wire *scope) registerSliceConstructor(entry *element, constructorType reflect.Type) {
+	wire.registerAggregator(entry, reflect.SliceOf(constructorType))
+}
+
+// registerMapConstructor registers an element for a map of given type. Elements
+// are keyed by their explicit key or key function when they have one, otherwise
+// by their Key method or by the name they're registered with
+//
+func (wire *scope) registerMapConstructor(entry *element, constructorType reflect.Type) {
+	wire.registerKeyedMapConstructor(entry, constructorType, constructorType)
+}
+
+// registerKeyedMapConstructor looks for the Key method of given key type
+//
+func (wire *scope) registerKeyedMapConstructor(entry *element, constructorType reflect.Type, keyedType reflect.Type) {
+
+	switch keyMethod, found := keyedType.MethodByName("Key"); {
+	case entry.keyOf != nil:
+	case found && keyMethod.Type.NumOut() > 0:
+		entry.keyType, entry.keyOf = keyMethod.Type.Out(0), keyByMethod
+	case entry.name != "":
+		entry.keyed(entry.name)
+	default:
+		return
+	}
+
+	wire.registerAggregator(entry, reflect.MapOf(entry.keyType, constructorType))
+}
+
+// keyed makes an element use given key in maps
+//
+func (entry *element) keyed(key interface{}) {
+	entry.key, entry.keyType = key, reflect.TypeOf(key)
+	entry.keyOf = func(constructed reflect.Value) reflect.Value {
+		return reflect.ValueOf(key)
 	}
 }
 
 func (scope *scope) Register(constructor interface{}) {
+	scope.register(&element{constructor: constructor})
+}
 
-	constructorType := ensureConstructorIsAFunction(constructor).Out(0)
+func (scope *scope) RegisterNamed(name string, constructor interface{}) {
+	scope.register(&element{constructor: constructor, name: name})
+}
+
+func (scope *scope) RegisterWithPriority(priority int, constructor interface{}) {
+	scope.register(&element{constructor: constructor, priority: priority})
+}
+
+func (scope *scope) RegisterKeyed(key interface{}, constructor interface{}) {
+	if key == nil {
+		panic("key can not be nil")
+	}
+
+	entry := &element{constructor: constructor}
+	entry.keyed(key)
+	scope.register(entry)
+}
+
+func (scope *scope) RegisterKeyedBy(keyFunction interface{}, constructor interface{}) {
+	constructorType, constructs := constructedType(ensureConstructorIsAFunction(constructor))
+	if !constructs {
+		panic("constructor does not construct anything")
+	}
+
+	keyFunctionType := reflect.TypeOf(keyFunction)
+	if keyFunctionType == nil || keyFunctionType.Kind() != reflect.Func || keyFunctionType.NumIn() != 1 || keyFunctionType.NumOut() != 1 ||
+		!constructorType.AssignableTo(keyFunctionType.In(0)) {
+		panic(fmt.Sprintf("key function should accept a %v and return its key", constructorType))
+	}
+
+	keyFunctionValue := reflect.ValueOf(keyFunction)
+	scope.register(&element{
+		constructor: constructor,
+		keyType:     keyFunctionType.Out(0),
+		keyOf: func(constructed reflect.Value) reflect.Value {
+			return keyFunctionValue.Call([]reflect.Value{constructed})[0]
+		}})
+}
+
+func (scope *scope) register(entry *element) {
+	constructor, name := entry.constructor, entry.name
+
+	constructorType, constructs := constructedType(ensureConstructorIsAFunction(constructor))
+	if !constructs {
+		panic("constructor does not construct anything")
+	}
+
+	scope.lock.Lock()
 
 	// ensure we know how to construct slices of given type
 	//
-	scope.registerSliceConstructor(constructor, constructorType)
-	scope.registerMapConstructor(constructor, constructorType)
+	scope.registerSliceConstructor(entry, constructorType)
+	scope.registerMapConstructor(entry, constructorType)
 
-	scope.constructorMapping[constructorType] = constructor
+	if name == "" {
+		scope.constructorMapping[constructorType] = constructor
+	} else {
+		scope.namedMapping[binding{objType: constructorType, name: name}] = constructor
+	}
+
+	scope.lock.Unlock()
+	scope.forgetLookups()
 
-	if constructorTag, found := FindConstructionTag(constructorType); found {
+	if constructorTag, found := constructionTag(constructor, constructorType); found {
 		if constructorTag.ShouldAutoConstruct() {
-			scope.ConstructByType(constructorType)
+			scope.ConstructNamed(constructorType, name)
 		}
 	}
 }
 
-func (scope *scope) doDecorateStruct(objValue reflect.Value, objType reflect.Type) {
+func (scope *scope) doDecorateStruct(res *resolution, objValue reflect.Value, objType reflect.Type) error {
 
-	decorators := FindStructDecorationTags(objType)
+	plan := planDecoration(objType)
 
-	for walk := 0; walk < objType.NumField(); walk++ {
+	for _, fieldType := range plan.fields {
 
-		field := objValue.Field(walk)
-		fieldType := objType.Field(walk)
+		field := objValue.FieldByIndex(fieldType.Index)
 
-		for _, decorator := range decorators {
+		// decorators continue the current resolution so they
+		// can not introduce dependency cycles unnoticed
+		//
+		wire := scope.resolver(res, dependency{owner: objType, field: fieldType.Name})
 
-			value, shouldSet := decorator.GetValueFor(scope, objValue, field, fieldType)
+		for _, decorator := range plan.decorators {
 
-			if shouldSet && value.Type().AssignableTo(field.Type()) {
+			value, shouldSet, err := valueFor(decorator, wire, objValue, field, fieldType)
+			if err != nil {
+				return err
+			}
+
+			if !shouldSet {
+				continue
+			}
+
+			assignable := value.Type().AssignableTo(field.Type())
+			if assignable {
 				internal.SetFieldValueByReflection(objValue, field, fieldType, value)
 			}
+
+			scope.trace(func() Event {
+				event := Event{Kind: FieldDecoratedEvent, Type: objType, Field: fieldType.Name, Tag: structDecorationTagType(decorator)}
+				if !assignable {
+					event.Kind = FieldSkippedEvent
+					event.Reason = fmt.Sprintf("%v is not assignable to %v", value.Type(), field.Type())
+				}
+				return event
+			})
 		}
 	}
+
+	return nil
 }
 
-func (scope *scope) doDecorate(objValue reflect.Value, objType reflect.Type) {
+func (scope *scope) doDecorate(res *resolution, objValue reflect.Value, objType reflect.Type) error {
 
 	if objType.Kind() == reflect.Ptr {
-		scope.doDecorate(objValue.Elem(), objType.Elem())
-		return
+		if objValue.IsNil() {
+			return nil
+		}
+		return scope.doDecorate(res, objValue.Elem(), objType.Elem())
 	}
 
 	if objType.Kind() == reflect.Struct {
-		scope.doDecorateStruct(objValue, objType)
-		return
+		return scope.doDecorateStruct(res, objValue, objType)
 	}
 
+	return nil
 }
 
-func (scope *scope) decorate(obj interface{}) interface{} {
-	scope.doDecorate(reflect.ValueOf(obj), reflect.TypeOf(obj))
-	return obj
+func (scope *scope) decorate(res *resolution, obj interface{}) (interface{}, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	return obj, scope.doDecorate(res, reflect.ValueOf(obj), reflect.TypeOf(obj))
 }
 
 // Inject takes a function and tries to call it by filling
@@ -234,68 +1073,304 @@ func (scope *scope) decorate(obj interface{}) interface{} {
 // })
 //
 func (scope *scope) Inject(use interface{}) {
-	scope.construct(use)
+	scope.resolver(newResolution(), dependency{}).Inject(use)
+}
+
+// TryInject is like Inject but will return an error instead of panicking
+//
+func (scope *scope) TryInject(use interface{}) error {
+	return scope.resolver(newResolution(), dependency{}).TryInject(use)
+}
+
+// InjectNamed is like Inject but uses given names to select named constructors
+//
+func (scope *scope) InjectNamed(use interface{}, names ...string) {
+	scope.resolver(newResolution(), dependency{}).InjectNamed(use, names...)
+}
+
+// TryInjectNamed is like InjectNamed but will return an error instead of panicking
+//
+func (scope *scope) TryInjectNamed(use interface{}, names ...string) error {
+	return scope.resolver(newResolution(), dependency{}).TryInjectNamed(use, names...)
+}
+
+// InjectContext is like Inject but hands over given context to all constructors
+//
+func (scope *scope) InjectContext(ctx context.Context, use interface{}) {
+	scope.resolver(newResolution(), dependency{}).InjectContext(ctx, use)
+}
+
+// TryInjectContext is like InjectContext but will return an error instead of panicking
+//
+func (scope *scope) TryInjectContext(ctx context.Context, use interface{}) error {
+	return scope.resolver(newResolution(), dependency{}).TryInjectContext(ctx, use)
 }
 
 // Construct takes a function and tries to call it by filling
 // in the arguments through the execution of registered constructor functions
 //
 func (scope *scope) Construct(use interface{}) interface{} {
-	return scope.construct(use)
+	return scope.resolver(newResolution(), dependency{}).Construct(use)
+}
+
+// TryConstruct is like Construct but will return an error instead of panicking
+//
+func (scope *scope) TryConstruct(use interface{}) (interface{}, error) {
+	return scope.resolver(newResolution(), dependency{}).TryConstruct(use)
 }
 
-func (scope *scope) construct(use interface{}) interface{} {
-	constructorType := ensureConstructorIsAFunction(use)
+// ConstructByType constructs a type by looking up its registered constructor
+// function.
+//
+func (scope *scope) ConstructByType(objType reflect.Type) interface{} {
+	return scope.resolver(newResolution(), dependency{}).ConstructByType(objType)
+}
+
+// TryConstructByType is like ConstructByType but will return an error when
+// the type is unknown or can not be constructed
+//
+func (scope *scope) TryConstructByType(objType reflect.Type) (interface{}, error) {
+	return scope.resolver(newResolution(), dependency{}).TryConstructByType(objType)
+}
+
+// ConstructNamed constructs a type by looking up the constructor function
+// registered with given name
+//
+func (scope *scope) ConstructNamed(objType reflect.Type, name string) interface{} {
+	return scope.resolver(newResolution(), dependency{}).ConstructNamed(objType, name)
+}
 
-	constructByReflection := func() interface{} {
-		in := make([]reflect.Value, constructorType.NumIn())
-		for i := range in {
-			if arg := scope.ConstructByType(constructorType.In(i)); arg != nil {
-				in[i] = reflect.ValueOf(arg)
-			} else {
-				panic(fmt.Sprintf("do not know how to construct %v", constructorType.In(i)))
+// TryConstructNamed is like ConstructNamed but will return an error when
+// the type is unknown or can not be constructed
+//
+func (scope *scope) TryConstructNamed(objType reflect.Type, name string) (interface{}, error) {
+	return scope.resolver(newResolution(), dependency{}).TryConstructNamed(objType, name)
+}
+
+// invoke calls a registered constructor which is either a constructor function
+// or an aggregator
+//
+func (scope *scope) invoke(res *resolution, constructor interface{}) (interface{}, error) {
+	if aggregator, ok := constructor.(*aggregator); ok {
+		return aggregator.aggregate(scope, res)
+	}
+	if alias, ok := constructor.(*alias); ok {
+		constructed, err := alias.resolve(scope, res)
+		if err != nil {
+			return nil, err
+		}
+		return scope.wrap(res, alias.objType, constructed)
+	}
+	return scope.construct(res, constructor)
+}
+
+// construct calls a function with constructed arguments. When names are given, they're
+// used to select named constructors for the arguments
+//
+func (scope *scope) construct(res *resolution, use interface{}, names ...string) (interface{}, error) {
+	plan, err := planConstructor(use)
+	if err != nil {
+		return nil, res.fail(reflect.TypeOf(function(use)), err)
+	}
+
+	outType, constructs := plan.outType, plan.constructs
+
+	// singletons are constructed by the scope that owns them, also when
+	// their constructor is used directly
+	//
+	if constructs && isOwned(use) {
+		if _, registrar, found := scope.findRegistration(outType, res.name); found {
+			if owner := scope.owner(registrar); owner != scope {
+				constructed, err := owner.construct(res, use, names...)
+				if err == nil {
+					constructed, err = scope.wrapOwned(res, owner, use, constructed)
+				}
+				if err == nil && res.root() {
+					err = res.session.start()
+				}
+				return constructed, err
 			}
+		}
+	}
 
+	constructByReflection := func() (interface{}, error) {
+		return scope.build(res, plan, use, names...)
+	}
+
+	construct := constructByReflection
+	if tag, found := plan.constructionTag(use); found && constructs {
+		construct = func() (interface{}, error) {
+			tagged := scope.resolver(res, dependency{})
+			tagged.build = plan.builder(res, use, names)
+			return applyTag(tag, tagged, outType, constructByReflection)
 		}
+	}
 
-		results := reflect.ValueOf(use).Call(in)
+	constructed, err := construct()
+	if err == nil && res.root() {
+		err = res.session.start()
+	}
+
+	return constructed, err
+}
 
-		if constructorType.NumOut() > 0 {
-			return scope.decorate(results[0].Interface())
+// builder returns a function that builds the object of a constructor within a given scope
+//
+func (plan *constructorPlan) builder(res *resolution, use interface{}, names []string) func(owner *scope) (interface{}, error) {
+	return func(owner *scope) (interface{}, error) {
+		return owner.build(res, plan, use, names...)
+	}
+}
+
+// build calls a constructor function within this scope, resolving its arguments
+// and decorating, initializing and wrapping the constructed object
+//
+func (scope *scope) build(res *resolution, plan *constructorPlan, use interface{}, names ...string) (interface{}, error) {
+	outType, constructs := plan.outType, plan.constructs
+	defer res.build()()
+
+	in := make([]reflect.Value, len(plan.in))
+	for i, inType := range plan.in {
+		name := ""
+		if i < len(names) {
+			name = names[i]
 		}
 
-		return nil
+		arg, err := scope.constructByType(res, inType, name, dependency{constructor: use})
+		if err != nil {
+			return nil, requestedBy(err, use)
+		}
+
+		if arg == nil {
+			in[i] = reflect.Zero(inType)
+		} else {
+			in[i] = reflect.ValueOf(arg)
+		}
+	}
 
+	// arguments are handed over to the caller when not resolving
+	// them for another constructor
+	//
+	if res.root() {
+		if err := res.session.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	// stop when the context of this resolution is done
+	//
+	if err := res.done(); err != nil {
+		if !constructs {
+			return nil, err
+		}
+		return nil, res.fail(outType, err)
 	}
 
-	if constructorType.NumOut() > 0 {
-		outType := constructorType.Out(0)
+	results := reflect.ValueOf(function(use)).Call(in)
 
-		if tag, found := FindConstructionTag(outType); found {
-			return tag.Apply(scope, outType, constructByReflection)
+	scope.trace(func() Event {
+		called := Event{Kind: ConstructorCalledEvent, Type: outType, Name: res.name, Constructor: constructorName(use)}
+		if plan.returnsError {
+			called.Err, _ = results[len(results)-1].Interface().(error)
 		}
+		return called
+	})
+
+	// stop when the constructor function reports an error
+	//
+	if plan.returnsError {
+		if err := results[len(results)-1]; !err.IsNil() {
+			if !constructs {
+				return nil, err.Interface().(error)
+			}
+			return nil, res.fail(outType, err.Interface().(error))
+		}
+	}
+
+	if !constructs {
+		return nil, nil
+	}
+
+	// decorate and initialize as part of the resolution of the constructed type
+	//
+	constructed := results[0].Interface()
+	constructing := res
+	if constructing.objType != outType {
+		constructing = constructing.push(outType)
+		defer constructing.build()()
+	}
+
+	if _, err := scope.decorate(constructing, constructed); err != nil {
+		return nil, err
+	}
+
+	if err := scope.initialize(constructing, constructed); err != nil {
+		return nil, err
 	}
 
-	return constructByReflection()
+	return scope.wrap(constructing, outType, constructed)
 }
 
-// ConstructByType constructs a type by looking up its registered constructor
-// function.
-//
-func (scope *scope) ConstructByType(objType reflect.Type) interface{} {
+func (scope *scope) constructByType(res *resolution, objType reflect.Type, name string, dep dependency) (interface{}, error) {
 
 	// when looking for a scope, always return the current scope
 	//
-	if objType == scopeType {
-		return scope
+	if objType == scopeType && name == "" {
+		return scope, nil
+	}
+
+	// when resolving for a context, always return this context
+	//
+	if objType == contextType && name == "" && res.ctx != nil {
+		return res.ctx, nil
+	}
+
+	entered, err := res.enter(objType, name, dep)
+	if err != nil {
+		return nil, err
+	}
+
+	// singletons are constructed by the scope that owns them
+	//
+	argConstructor, owner, found := scope.findRegistration(objType, name)
+	if found && isOwned(argConstructor) {
+		owner = scope.owner(owner)
+	} else {
+		owner = scope
 	}
 
-	argConstructor, found := scope.findConstructor(objType)
 	if !found {
-		if objType.Kind() == reflect.Slice {
-			return internal.CreateSliceWithValues(objType).Interface()
+		if objType.Kind() == reflect.Slice && name == "" {
+			return internal.CreateSliceWithValues(objType).Interface(), nil
 		}
-		return nil
+
+		// when resolving without a context, use an empty one
+		//
+		if objType == contextType && name == "" {
+			return context.Background(), nil
+		}
+
+		if provider, ok := scope.provider(entered, objType, name); ok {
+			return provider, nil
+		}
+
+		bound, err := scope.findImplementation(objType, name)
+		if err != nil {
+			return nil, entered.fail(objType, err)
+		}
+		if bound == nil {
+			return nil, entered.unknown()
+		}
+		argConstructor = bound
+	}
+
+	constructed, err := owner.invoke(entered, argConstructor)
+	if err == nil {
+		constructed, err = scope.wrapOwned(entered, owner, argConstructor, constructed)
+	}
+	if err == nil && res.root() {
+		err = res.session.start()
 	}
-	return scope.Construct(argConstructor)
+
+	return constructed, err
 }
@@ -1,7 +1,9 @@
 package wired_test
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/okke/wired"
@@ -452,3 +454,675 @@ func TestShouldUsesMockInSlicesAndMaps(t *testing.T) {
 
 	})
 }
+
+// ------ Test error returning variants ----
+
+type needsUnknown struct {
+	unknown *unknownStruct
+}
+
+func newNeedsUnknown(unknown *unknownStruct) *needsUnknown {
+	return &needsUnknown{unknown: unknown}
+}
+
+type needsNeedsUnknown struct {
+	needs *needsUnknown
+}
+
+func newNeedsNeedsUnknown(needs *needsUnknown) *needsNeedsUnknown {
+	return &needsNeedsUnknown{needs: needs}
+}
+
+var unknownStructType = reflect.TypeOf((*unknownStruct)(nil))
+var needsUnknownType = reflect.TypeOf((*needsUnknown)(nil))
+var needsNeedsUnknownType = reflect.TypeOf((*needsNeedsUnknown)(nil))
+
+func TestTryConstructByTypeShouldReportUnknownType(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+
+		if _, err := scope.TryConstructByType(unknownStructType); err == nil {
+			t.Fatal("expected an error")
+		} else if resolutionErr, ok := err.(*wired.ResolutionError); !ok {
+			t.Fatal("expected a resolution error, not", err)
+		} else if resolutionErr.Type != unknownStructType {
+			t.Error("expected unknown type to be reported, not", resolutionErr.Type)
+		}
+
+		if constructed := scope.ConstructByType(unknownStructType); constructed != nil {
+			t.Error("expected nil for an unknown type, not", constructed)
+		}
+	})
+}
+
+func TestTryConstructShouldReportPath(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newNeedsUnknown)
+		scope.Register(newNeedsNeedsUnknown)
+
+		_, err := scope.TryConstructByType(needsNeedsUnknownType)
+		resolutionErr, ok := err.(*wired.ResolutionError)
+		if !ok {
+			t.Fatal("expected a resolution error, not", err)
+		}
+
+		if resolutionErr.Type != unknownStructType {
+			t.Error("expected unknown type to be reported, not", resolutionErr.Type)
+		}
+
+		expectedPath := []reflect.Type{needsNeedsUnknownType, needsUnknownType, unknownStructType}
+		if !reflect.DeepEqual(resolutionErr.Path, expectedPath) {
+			t.Error("expected path", expectedPath, "not", resolutionErr.Path)
+		}
+
+		if reflect.ValueOf(resolutionErr.Constructor).Pointer() != reflect.ValueOf(newNeedsUnknown).Pointer() {
+			t.Error("expected newNeedsUnknown to be reported as constructor")
+		}
+
+		if !strings.Contains(err.Error(), "newNeedsUnknown") {
+			t.Error("expected constructor name in error message, not", err.Error())
+		}
+	})
+}
+
+func TestTryInjectShouldNotPanic(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newNeedsUnknown)
+
+		called := false
+		if err := scope.TryInject(func(needs *needsUnknown) {
+			called = true
+		}); err == nil {
+			t.Error("expected an error")
+		}
+
+		if called {
+			t.Error("function should not be called when arguments are missing")
+		}
+
+		if _, err := scope.TryConstruct("chipotle"); err == nil {
+			t.Error("expected an error when constructing with a non function")
+		}
+	})
+}
+
+func TestPanickingVariantsShouldPanicWithResolutionError(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(*wired.ResolutionError); !ok {
+			t.Error("expected to panic with a resolution error")
+		}
+	}()
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newNeedsUnknown)
+		scope.ConstructByType(needsUnknownType)
+	})
+}
+
+// ------ Test constructors returning errors ----
+
+var errNoChipotles = errors.New("out of chipotles")
+
+type failingStruct struct {
+}
+
+func newFailingStruct() (*failingStruct, error) {
+	return nil, errNoChipotles
+}
+
+type needsFailing struct {
+	failing *failingStruct
+}
+
+func newNeedsFailing(failing *failingStruct) *needsFailing {
+	return &needsFailing{failing: failing}
+}
+
+func newSucceedingStruct() (*emptyStruct, error) {
+	return &emptyStruct{}, nil
+}
+
+func TestConstructorReturningError(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newFailingStruct)
+		scope.Register(newNeedsFailing)
+		scope.Register(newSucceedingStruct)
+
+		if empty, err := scope.TryConstruct(func(empty *emptyStruct) *emptyStruct {
+			return empty
+		}); err != nil || empty.(*emptyStruct) == nil {
+			t.Error("expected an empty struct without errors, not", empty, err)
+		}
+
+		called := false
+		err := scope.TryInject(func(needs *needsFailing) {
+			called = true
+		})
+
+		if called {
+			t.Error("function should not be called when a constructor fails")
+		}
+
+		if !errors.Is(err, errNoChipotles) {
+			t.Fatal("expected constructor error to be returned, not", err)
+		}
+
+		resolutionErr := err.(*wired.ResolutionError)
+		if resolutionErr.Type != reflect.TypeOf((*failingStruct)(nil)) {
+			t.Error("expected failing struct to be reported, not", resolutionErr.Type)
+		}
+	})
+}
+
+func TestInjectReturningError(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newEmptyStruct)
+
+		if err := scope.TryInject(func(empty *emptyStruct) error {
+			return errNoChipotles
+		}); err != errNoChipotles {
+			t.Error("expected error of injected function to be returned, not", err)
+		}
+
+		if err := scope.TryInject(func(empty *emptyStruct) error {
+			return nil
+		}); err != nil {
+			t.Error("expected no error, not", err)
+		}
+	})
+}
+
+// ------ Test dependency cycles ----
+
+type cyclicA struct {
+	b *cyclicB
+}
+
+type cyclicB struct {
+	a *cyclicA
+}
+
+func newCyclicA(b *cyclicB) *cyclicA {
+	return &cyclicA{b: b}
+}
+
+func newCyclicB(a *cyclicA) *cyclicB {
+	return &cyclicB{a: a}
+}
+
+type autoWiredCyclicB struct {
+	wired.AutoWire
+
+	A *cyclicA
+}
+
+func newAutoWiredCyclicB() *autoWiredCyclicB {
+	return &autoWiredCyclicB{}
+}
+
+func newCyclicAWithAutoWiredB(b *autoWiredCyclicB) *cyclicA {
+	return &cyclicA{}
+}
+
+type cyclicFactory struct {
+	wired.Factory
+}
+
+func (cyclicFactory *cyclicFactory) ConstructB(a *cyclicA) *cyclicB {
+	return &cyclicB{a: a}
+}
+
+func newCyclicFactory() *cyclicFactory {
+	return &cyclicFactory{}
+}
+
+var cyclicAType = reflect.TypeOf((*cyclicA)(nil))
+var cyclicBType = reflect.TypeOf((*cyclicB)(nil))
+
+func expectCycle(t *testing.T, err error, via ...string) {
+	var cycleErr *wired.CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatal("expected a dependency cycle, not", err)
+	}
+
+	if first, last := cycleErr.Cycle[0], cycleErr.Cycle[len(cycleErr.Cycle)-1]; first != last {
+		t.Error("expected cycle to start and end with the same type, not", first, last)
+	}
+
+	for _, expected := range via {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected '%s' to be mentioned in '%s'", expected, err.Error())
+		}
+	}
+}
+
+func TestCycleThroughConstructorArguments(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newCyclicA)
+		scope.Register(newCyclicB)
+
+		_, err := scope.TryConstructByType(cyclicAType)
+		expectCycle(t, err, "*wired_test.cyclicA -> *wired_test.cyclicB -> *wired_test.cyclicA", "argument of github.com/okke/wired_test.newCyclicA", "argument of github.com/okke/wired_test.newCyclicB")
+	})
+}
+
+func TestCycleThroughAutoWireField(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newCyclicAWithAutoWiredB)
+		scope.Register(newAutoWiredCyclicB)
+
+		_, err := scope.TryConstructByType(cyclicAType)
+		expectCycle(t, err, "field A of wired_test.autoWiredCyclicB")
+	})
+}
+
+func TestCycleAcrossScopes(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newCyclicA)
+
+		scope.Go(func(inner wired.Scope) {
+			inner.Register(newCyclicB)
+
+			_, err := inner.TryConstructByType(cyclicBType)
+			expectCycle(t, err)
+		})
+	})
+}
+
+func TestCycleThroughFactory(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newCyclicA)
+		scope.Register(newCyclicFactory)
+
+		err := scope.TryInject(func(a *cyclicA) {})
+		expectCycle(t, err, "ConstructB")
+	})
+}
+
+type database struct {
+	name string
+}
+
+type usesDatabases struct {
+	wired.AutoWire
+
+	Primary *database
+	Replica *database `autowire:"replica"`
+}
+
+func newUsesDatabases() *usesDatabases {
+	return &usesDatabases{}
+}
+
+type namedSingleton struct {
+	wired.Singleton
+
+	name string
+}
+
+var databaseType = reflect.TypeOf((*database)(nil))
+var namedSingletonType = reflect.TypeOf((*namedSingleton)(nil))
+
+func registerDatabases(scope wired.Scope) {
+	scope.Register(func() *database {
+		return &database{name: "primary"}
+	})
+	scope.RegisterNamed("replica", func() *database {
+		return &database{name: "replica"}
+	})
+}
+
+func TestInjectNamed(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		registerDatabases(scope)
+
+		scope.InjectNamed(func(replica *database, primary *database) {
+			if replica.name != "replica" {
+				t.Error("expected replica, not", replica.name)
+			}
+			if primary.name != "primary" {
+				t.Error("expected primary, not", primary.name)
+			}
+		}, "replica")
+
+		scope.Inject(func(primary *database) {
+			if primary.name != "primary" {
+				t.Error("expected unnamed constructor to be used, not", primary.name)
+			}
+		})
+	})
+}
+
+func TestConstructNamed(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		registerDatabases(scope)
+
+		if replica := scope.ConstructNamed(databaseType, "replica").(*database); replica.name != "replica" {
+			t.Error("expected replica, not", replica.name)
+		}
+
+		if unknown := scope.ConstructNamed(databaseType, "backup"); unknown != nil {
+			t.Error("expected nil for an unknown name, not", unknown)
+		}
+
+		_, err := scope.TryConstructNamed(databaseType, "backup")
+		if resolutionErr, ok := err.(*wired.ResolutionError); !ok {
+			t.Fatal("expected a resolution error, not", err)
+		} else if resolutionErr.Name != "backup" {
+			t.Error("expected name to be reported, not", resolutionErr.Name)
+		}
+
+		if !strings.Contains(err.Error(), "named backup") {
+			t.Error("expected name in error message, not", err.Error())
+		}
+	})
+}
+
+func TestAutoWireNamedField(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		registerDatabases(scope)
+		scope.Register(newUsesDatabases)
+
+		scope.Inject(func(uses *usesDatabases) {
+			if uses.Primary == nil || uses.Primary.name != "primary" {
+				t.Error("expected primary to be wired, not", uses.Primary)
+			}
+			if uses.Replica == nil || uses.Replica.name != "replica" {
+				t.Error("expected replica to be wired, not", uses.Replica)
+			}
+		})
+	})
+}
+
+func TestSlicesShouldContainNamedConstructors(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		registerDatabases(scope)
+
+		scope.Go(func(inner wired.Scope) {
+			inner.RegisterNamed("backup", func() *database {
+				return &database{name: "backup"}
+			})
+
+			inner.Inject(func(databases []*database) {
+				if len(databases) != 3 {
+					t.Error("expected 3 databases, not", len(databases))
+				}
+			})
+		})
+	})
+}
+
+func TestNamedSingletons(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *namedSingleton {
+			return &namedSingleton{name: "default"}
+		})
+		scope.RegisterNamed("other", func() *namedSingleton {
+			return &namedSingleton{name: "other"}
+		})
+
+		scope.InjectNamed(func(first *namedSingleton, second *namedSingleton, third *namedSingleton) {
+			if first != third {
+				t.Error("expected the same named singleton")
+			}
+			if first == second || second.name != "default" {
+				t.Error("expected named and unnamed singletons to differ")
+			}
+		}, "other", "", "other")
+
+		if found, _ := scope.FindSingleton(namedSingletonType); found.(*namedSingleton).name != "default" {
+			t.Error("expected unnamed singleton to be found")
+		}
+	})
+}
+
+type middleware interface {
+	Name() string
+}
+
+type namedMiddleware struct {
+	name string
+}
+
+func (namedMiddleware *namedMiddleware) Name() string {
+	return namedMiddleware.name
+}
+
+type orderedMiddleware struct {
+	namedMiddleware
+
+	order int
+}
+
+func (orderedMiddleware *orderedMiddleware) Order() int {
+	return orderedMiddleware.order
+}
+
+func middlewareNamed(name string) func() middleware {
+	return func() middleware {
+		return &namedMiddleware{name: name}
+	}
+}
+
+func middlewareNames(chain []middleware) string {
+	names := make([]string, len(chain))
+	for i, m := range chain {
+		names[i] = m.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSlicesShouldBeInOrderOfRegistration(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(middlewareNamed("first"))
+		scope.Register(middlewareNamed("second"))
+
+		scope.Go(func(sub wired.Scope) {
+			sub.Register(middlewareNamed("third"))
+			scope.Register(middlewareNamed("fourth"))
+
+			if names := middlewareNames(wired.MustGet[[]middleware](sub)); names != "first,second,third,fourth" {
+				t.Error("expected slice in order of registration, not", names)
+			}
+		})
+
+		if names := middlewareNames(wired.MustGet[[]middleware](scope)); names != "first,second,fourth" {
+			t.Error("expected slice without elements of sub scope, not", names)
+		}
+	})
+}
+
+func TestSlicesShouldBeOrderedByPriority(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(middlewareNamed("logging"))
+		scope.RegisterWithPriority(-10, middlewareNamed("recovery"))
+		scope.RegisterWithPriority(10, middlewareNamed("compression"))
+		scope.Register(func() middleware {
+			return &orderedMiddleware{namedMiddleware: namedMiddleware{name: "auth"}, order: 5}
+		})
+
+		if names := middlewareNames(wired.MustGet[[]middleware](scope)); names != "recovery,logging,auth,compression" {
+			t.Error("expected slice ordered by priority, not", names)
+		}
+	})
+}
+
+func TestExcludeShouldLeaveElementsOut(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newAWSDriver)
+		scope.Register(newAzureDriver)
+
+		scope.Go(func(sub wired.Scope) {
+			sub.Exclude(newAWSDriver)
+
+			if drivers := wired.MustGet[[]*driver](sub); len(drivers) != 1 || drivers[0].name != "azure" {
+				t.Error("expected aws driver to be excluded from slice, not", drivers)
+			}
+			if drivers := wired.MustGet[map[string]*driver](sub); len(drivers) != 1 || drivers["aws"] != nil {
+				t.Error("expected aws driver to be excluded from map, not", drivers)
+			}
+		})
+
+		if drivers := wired.MustGet[[]*driver](scope); len(drivers) != 2 {
+			t.Error("expected exclusion not to affect the parent scope, not", drivers)
+		}
+	})
+}
+
+type excludedListener struct {
+	name string
+}
+
+func newAuditListener() *excludedListener {
+	return &excludedListener{name: "audit"}
+}
+
+func newMetricsListener() *excludedListener {
+	return &excludedListener{name: "metrics"}
+}
+
+func TestExcludeShouldLeaveOutUnnamedElements(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newAuditListener)
+		scope.RegisterWithPriority(1, newMetricsListener)
+
+		scope.Go(func(sub wired.Scope) {
+			sub.Exclude(newAuditListener)
+
+			if listeners := wired.MustGet[[]*excludedListener](sub); len(listeners) != 1 || listeners[0].name != "metrics" {
+				t.Error("expected audit listener to be excluded, not", listeners)
+			}
+		})
+	})
+}
+
+func TestExcludeShouldLeaveOutElementsByKey(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newAWSDriver)
+		scope.Register(newAzureDriver)
+		scope.ExcludeKey(wired.TypeOf[*driver](), "aws")
+
+		if drivers := wired.MustGet[[]*driver](scope); len(drivers) != 1 || drivers[0].name != "azure" {
+			t.Error("expected aws driver to be excluded by its key, not", drivers)
+		}
+	})
+}
+
+func TestExcludeShouldOnlyLeaveOutElementsWithGivenName(t *testing.T) {
+	replica := func(name string) func() *validatedReplica {
+		return func() *validatedReplica { return &validatedReplica{} }
+	}
+
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterNamed("primary", replica("primary"))
+		scope.RegisterNamed("secondary", replica("secondary"))
+		scope.ExcludeKey(wired.TypeOf[*validatedReplica](), "primary")
+
+		if replicas := wired.MustGet[map[string]*validatedReplica](scope); len(replicas) != 1 || replicas["secondary"] == nil {
+			t.Error("expected only the primary replica to be excluded, not", replicas)
+		}
+	})
+}
+
+func TestSubScopeShouldReplaceMapElementsByKey(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newAWSDriver)
+		scope.Register(newAzureDriver)
+
+		scope.Go(func(sub wired.Scope) {
+			replacement := &driver{name: "aws"}
+			sub.Register(func() *driver { return replacement })
+
+			if drivers := wired.MustGet[map[string]*driver](sub); len(drivers) != 2 || drivers["aws"] != replacement {
+				t.Error("expected aws driver to be replaced, not", drivers)
+			}
+		})
+	})
+}
+
+func TestDuplicateMapKeysShouldBeReported(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newAWSDriver)
+		scope.Register(func() *driver { return &driver{name: "aws"} })
+
+		var duplicate *wired.DuplicateKeyError
+		if _, err := wired.Get[map[string]*driver](scope); !errors.As(err, &duplicate) || duplicate.Key != "aws" {
+			t.Error("expected duplicate key aws to be reported, not", err)
+		}
+	})
+}
+
+type blobStore interface {
+	Store() string
+}
+
+type bucketStore struct {
+	bucket string
+}
+
+func (bucketStore *bucketStore) Store() string {
+	return bucketStore.bucket
+}
+
+type keyedStore struct{}
+
+func (keyedStore *keyedStore) Store() string {
+	return "keyed"
+}
+
+func (keyedStore *keyedStore) Key() string {
+	return "by-method"
+}
+
+func bucketNamed(bucket string) func() blobStore {
+	return func() blobStore {
+		return &bucketStore{bucket: bucket}
+	}
+}
+
+func TestMapsShouldBeKeyedByName(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterNamed("images", bucketNamed("images"))
+		scope.RegisterNamed("videos", bucketNamed("videos"))
+
+		stores := wired.MustGet[map[string]blobStore](scope)
+		if len(stores) != 2 || stores["images"].Store() != "images" || stores["videos"].Store() != "videos" {
+			t.Error("expected stores keyed by name, not", stores)
+		}
+	})
+}
+
+type storeKind int
+
+func TestMapsShouldBeKeyedExplicitly(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterKeyed(storeKind(1), bucketNamed("images"))
+		scope.RegisterKeyedBy(func(store blobStore) string { return "bucket-" + store.Store() }, bucketNamed("videos"))
+
+		if stores := wired.MustGet[map[storeKind]blobStore](scope); len(stores) != 1 || stores[1].Store() != "images" {
+			t.Error("expected store keyed by given key, not", stores)
+		}
+		if stores := wired.MustGet[map[string]blobStore](scope); len(stores) != 1 || stores["bucket-videos"] == nil {
+			t.Error("expected store keyed by key function, not", stores)
+		}
+	})
+}
+
+func TestMapsOfInterfacesShouldBeKeyedByImplementation(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterAs(func() *keyedStore { return &keyedStore{} }, wired.TypeOf[blobStore]())
+		scope.RegisterNamed("videos", bucketNamed("videos"))
+
+		stores := wired.MustGet[map[string]blobStore](scope)
+		if len(stores) != 2 || stores["by-method"].Store() != "keyed" || stores["videos"] == nil {
+			t.Error("expected implementations with their own keys, not", stores)
+		}
+	})
+}
+
+func TestRegisterKeyedByShouldPanicOnInvalidKeyFunction(t *testing.T) {
+	defer internal.ShouldPanic(t)()
+
+	wired.Go(func(scope wired.Scope) {
+		scope.RegisterKeyedBy(func(driver *driver) string { return driver.name }, bucketNamed("images"))
+	})
+}
@@ -3,7 +3,9 @@ package wired
 import "reflect"
 
 // Singleton is a struct that can be mixed into another struct
-// to express this struct must be used as singleton
+// to express this struct must be used as singleton. A singleton is owned by
+// the scope its constructor is registered in, it's constructed using the
+// constructors of this scope and shared by all its sub scopes
 //
 type Singleton struct {
 }
@@ -11,6 +13,20 @@ type Singleton struct {
 type singleton struct {
 }
 
+// singletonScope is implemented by scopes that are able to construct
+// a singleton exactly once for every type and name
+//
+type singletonScope interface {
+	singleton(objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error)
+}
+
+// ownedTag is implemented by construction tags of objects that are owned by
+// the scope their constructor is registered in
+//
+type ownedTag interface {
+	ownedByRegistrar()
+}
+
 func init() {
 	RegisterConstructionTag(reflect.TypeOf((*Singleton)(nil)).Elem(), &singleton{})
 }
@@ -18,16 +34,80 @@ func init() {
 // Apply applies singleton creation logic
 //
 func (singleton *singleton) Apply(scope Scope, objType reflect.Type, constructor func() interface{}) interface{} {
+	return tryApply(singleton, scope, objType, constructor)
+}
+
+// TryApply applies singleton creation logic
+//
+func (singleton *singleton) TryApply(scope Scope, objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error) {
+
+	if wire, ok := scope.(singletonScope); ok {
+		return wire.singleton(objType, constructor)
+	}
 
 	if object, found := scope.FindSingleton(objType); found {
-		return object
+		return object, nil
+	}
+
+	constructed, err := constructor()
+	if err != nil {
+		return nil, err
 	}
 
-	constructed := constructor()
 	scope.RegisterSingleton(objType, constructed)
-	return constructed
+	return constructed, nil
 }
 
 func (singleton *singleton) ShouldAutoConstruct() bool {
 	return false
 }
+
+func (singleton *singleton) ownedByRegistrar() {
+}
+
+// isOwned tells whether the objects a constructor constructs are owned by the
+// scope the constructor is registered in
+//
+func isOwned(constructor interface{}) bool {
+	switch constructor.(type) {
+	case *alias, *aggregator:
+		return false
+	}
+
+	plan, err := planConstructor(constructor)
+	if err != nil || !plan.constructs {
+		return false
+	}
+
+	tag, found := plan.constructionTag(constructor)
+	if !found {
+		return false
+	}
+
+	_, owned := tag.(ownedTag)
+	return owned
+}
+
+func (scope *scope) RebuildSingletons() {
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	scope.rebuildSingletons = true
+}
+
+// owner returns the scope that owns the singletons of a constructor registered in
+// given scope, when they are resolved within this scope. That's the nearest scope
+// rebuilding singletons, or otherwise the registering scope itself
+//
+func (scope *scope) owner(registrar *scope) *scope {
+	for walk := scope; walk != nil && walk != registrar; walk = walk.parent {
+		walk.lock.RLock()
+		rebuilds := walk.rebuildSingletons
+		walk.lock.RUnlock()
+
+		if rebuilds {
+			return walk
+		}
+	}
+	return registrar
+}
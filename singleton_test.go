@@ -1,6 +1,7 @@
 package wired_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/okke/wired"
@@ -75,8 +76,8 @@ func TestConstructSingletonsWithMultipleScopes(t *testing.T) {
 
 		outerUsage := scope.Construct(newStructWithSingleTon).(*structWithSingleton)
 
-		if innerUsage.S == outerUsage.S {
-			t.Error("singletons should be scoped by default", innerUsage.S, outerUsage.S)
+		if innerUsage.S != outerUsage.S {
+			t.Error("singletons should be owned by the scope registering them", innerUsage.S, outerUsage.S)
 		}
 
 	})
@@ -98,3 +99,122 @@ func TestConstructSingletonsWithMultipleScopes(t *testing.T) {
 
 	})
 }
+
+type failingSingleton struct {
+	wired.Singleton
+}
+
+var failingSingletonAttempts = 0
+
+func newFailingSingleton() (*failingSingleton, error) {
+	failingSingletonAttempts = failingSingletonAttempts + 1
+	if failingSingletonAttempts == 1 {
+		return nil, errors.New("not yet")
+	}
+	return &failingSingleton{}, nil
+}
+
+func TestFailedSingletonShouldNotBeCached(t *testing.T) {
+
+	failingSingletonAttempts = 0
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newFailingSingleton)
+
+		if _, err := scope.TryConstruct(newFailingSingleton); err == nil {
+			t.Fatal("expected first construction to fail")
+		}
+
+		first, err := scope.TryConstruct(newFailingSingleton)
+		if err != nil {
+			t.Fatal("expected second construction to succeed, not", err)
+		}
+
+		if second := scope.Construct(newFailingSingleton); second != first {
+			t.Error("expected singleton to be cached after construction succeeded")
+		}
+	})
+}
+
+type singletonDependency struct {
+	name string
+}
+
+type singletonWithDependency struct {
+	wired.Singleton
+
+	dependency *singletonDependency
+}
+
+func newSingletonWithDependency(dependency *singletonDependency) *singletonWithDependency {
+	return &singletonWithDependency{dependency: dependency}
+}
+
+func TestSingletonShouldBeConstructedWithBindingsOfItsOwner(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *singletonDependency { return &singletonDependency{name: "real"} })
+		scope.Register(newSingletonWithDependency)
+
+		scope.Go(func(test wired.Scope) {
+			test.Register(func() *singletonDependency { return &singletonDependency{name: "mock"} })
+
+			if singleton := wired.MustGet[*singletonWithDependency](test); singleton.dependency.name != "real" {
+				t.Error("expected mock not to leak into a singleton of the parent scope")
+			}
+		})
+	})
+}
+
+func TestRebuildSingletonsShouldUseBindingsOfSubScope(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *singletonDependency { return &singletonDependency{name: "real"} })
+		scope.Register(newSingletonWithDependency)
+
+		real := wired.MustGet[*singletonWithDependency](scope)
+
+		scope.Go(func(test wired.Scope) {
+			test.RebuildSingletons()
+			test.Register(func() *singletonDependency { return &singletonDependency{name: "mock"} })
+
+			rebuilt := wired.MustGet[*singletonWithDependency](test)
+			if rebuilt == real || rebuilt.dependency.name != "mock" {
+				t.Error("expected singleton to be rebuilt using the mock")
+			}
+
+			test.Go(func(inner wired.Scope) {
+				if wired.MustGet[*singletonWithDependency](inner) != rebuilt {
+					t.Error("expected sub scopes to share the rebuilt singleton")
+				}
+			})
+		})
+
+		if wired.MustGet[*singletonWithDependency](scope) != real {
+			t.Error("expected rebuilding not to affect the parent scope")
+		}
+	})
+}
+
+func TestConstructShouldUseSingletonOfOwner(t *testing.T) {
+	constructed := 0
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *singletonDependency { return &singletonDependency{name: "real"} })
+		scope.Register(func(dependency *singletonDependency) *singletonWithDependency {
+			constructed++
+			return newSingletonWithDependency(dependency)
+		})
+
+		real := wired.MustGet[*singletonWithDependency](scope)
+
+		scope.Go(func(test wired.Scope) {
+			test.Register(func() *singletonDependency { return &singletonDependency{name: "mock"} })
+
+			if singleton := test.Construct(newSingletonWithDependency).(*singletonWithDependency); singleton != real || singleton.dependency.name != "real" {
+				t.Error("expected singleton of parent scope to be used, not", singleton)
+			}
+		})
+	})
+
+	if constructed != 1 {
+		t.Error("expected singleton to be constructed once, not", constructed)
+	}
+}
@@ -9,6 +9,19 @@ type ConstructionTag interface {
 	ShouldAutoConstruct() bool
 }
 
+// ErrorConstructionTag can be implemented by construction tags that report errors
+// instead of panicking. Wired uses TryApply instead of Apply when it's implemented
+//
+type ErrorConstructionTag interface {
+	ConstructionTag
+
+	// TryApply is called to construct an object of given type. The provided constructor
+	// function will construct a new object. When it returns an error, TryApply should
+	// return this error
+	//
+	TryApply(scope Scope, objType reflect.Type, constructor func() (interface{}, error)) (interface{}, error)
+}
+
 // StructDecorationTag can be used to initialize a struct after it has been constructed
 //
 type StructDecorationTag interface {
@@ -19,6 +32,87 @@ type StructDecorationTag interface {
 	GetValueFor(wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool)
 }
 
+// ErrorStructDecorationTag can be implemented by struct decoration tags that report
+// errors instead of panicking. Wired uses TryGetValueFor instead of GetValueFor when
+// it's implemented
+//
+type ErrorStructDecorationTag interface {
+	StructDecorationTag
+
+	// TryGetValueFor is like GetValueFor. When a value should be there but could not
+	// be constructed, it returns an error
+	//
+	TryGetValueFor(wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool, error)
+}
+
+// constructionFailure carries the error of a constructor through a construction
+// tag that's not able to return errors
+//
+type constructionFailure struct {
+	err error
+}
+
+// applyTag applies a construction tag. Construction errors that make a tag panic
+// are returned as error
+//
+func applyTag(tag ConstructionTag, scope Scope, objType reflect.Type, constructor func() (interface{}, error)) (constructed interface{}, err error) {
+	if tag, ok := tag.(ErrorConstructionTag); ok {
+		return tag.TryApply(scope, objType, constructor)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			failure, ok := recovered.(*constructionFailure)
+			if !ok {
+				panic(recovered)
+			}
+			err = failure.err
+		}
+	}()
+
+	return tag.Apply(scope, objType, func() interface{} {
+		constructed, err := constructor()
+		if err != nil {
+			panic(&constructionFailure{err: err})
+		}
+		return constructed
+	}), nil
+}
+
+// tryApply implements Apply of construction tags that are able to return errors
+//
+func tryApply(tag ErrorConstructionTag, scope Scope, objType reflect.Type, constructor func() interface{}) interface{} {
+	constructed, err := tag.TryApply(scope, objType, func() (interface{}, error) {
+		return constructor(), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return constructed
+}
+
+// valueFor asks a struct decoration tag for the value of a field
+//
+func valueFor(decorator StructDecorationTag, wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool, error) {
+	if decorator, ok := decorator.(ErrorStructDecorationTag); ok {
+		return decorator.TryGetValueFor(wire, obj, field, fieldType)
+	}
+
+	value, found := decorator.GetValueFor(wire, obj, field, fieldType)
+	return value, found, nil
+}
+
+// tryGetValueFor implements GetValueFor of struct decoration tags that are able
+// to return errors
+//
+func tryGetValueFor(decorator ErrorStructDecorationTag, wire Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool) {
+	value, found, err := decorator.TryGetValueFor(wire, obj, field, fieldType)
+	if err != nil {
+		panic(err)
+	}
+	return value, found
+}
+
 var constructionTags = make(map[reflect.Type]ConstructionTag, 10)
 var structDecorationTags = make(map[reflect.Type]StructDecorationTag, 10)
 
@@ -26,12 +120,29 @@ var structDecorationTags = make(map[reflect.Type]StructDecorationTag, 10)
 //
 func RegisterConstructionTag(objType reflect.Type, tag ConstructionTag) {
 	constructionTags[objType] = tag
+	forgetPlans()
 }
 
 // RegisterStructDecorationTag connects a struct tag to its decoration logic
 //
 func RegisterStructDecorationTag(objType reflect.Type, tag StructDecorationTag) {
 	structDecorationTags[objType] = tag
+	forgetPlans()
+}
+
+// structDecorationTagType returns the tag a struct decorator is registered for
+//
+func structDecorationTagType(decorator StructDecorationTag) reflect.Type {
+	if !reflect.TypeOf(decorator).Comparable() {
+		return nil
+	}
+
+	for tagType, registered := range structDecorationTags {
+		if registered == decorator {
+			return tagType
+		}
+	}
+	return nil
 }
 
 // FindConstructionTag looks at the fields of a given type and returns
@@ -0,0 +1,75 @@
+package wired_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type counted struct{}
+
+type countingTag struct {
+	applied int
+}
+
+func (countingTag *countingTag) Apply(scope wired.Scope, objType reflect.Type, constructor func() interface{}) interface{} {
+	countingTag.applied++
+	return constructor()
+}
+
+func (countingTag *countingTag) ShouldAutoConstruct() bool {
+	return false
+}
+
+type countedStruct struct {
+	counted
+}
+
+func TestConstructionTagWithoutErrorsShouldBeApplied(t *testing.T) {
+	tag := &countingTag{}
+	wired.RegisterConstructionTag(reflect.TypeOf(counted{}), tag)
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *countedStruct { return &countedStruct{} })
+
+		if wired.MustGet[*countedStruct](scope) == nil || tag.applied != 1 {
+			t.Error("expected tag to be applied once, not", tag.applied)
+		}
+
+		failing := errors.New("failed")
+		if _, err := scope.TryConstruct(func() (*countedStruct, error) { return nil, failing }); !errors.Is(err, failing) {
+			t.Error("expected error of constructor to be returned through the tag, not", err)
+		}
+	})
+}
+
+type greeted struct{}
+
+type greetingTag struct{}
+
+func (greetingTag *greetingTag) GetValueFor(wire wired.Scope, obj reflect.Value, field reflect.Value, fieldType reflect.StructField) (reflect.Value, bool) {
+	if fieldType.Name != "Greeting" {
+		return reflect.ValueOf(nil), false
+	}
+	return reflect.ValueOf("hello"), true
+}
+
+type greetedStruct struct {
+	greeted
+
+	Greeting string
+}
+
+func TestStructDecorationTagWithoutErrorsShouldSetFields(t *testing.T) {
+	wired.RegisterStructDecorationTag(reflect.TypeOf(greeted{}), &greetingTag{})
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *greetedStruct { return &greetedStruct{} })
+
+		if greeting := wired.MustGet[*greetedStruct](scope).Greeting; greeting != "hello" {
+			t.Error("expected field to be set by tag, not", greeting)
+		}
+	})
+}
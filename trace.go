@@ -0,0 +1,161 @@
+package wired
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// EventKind tells what happened while resolving
+//
+type EventKind string
+
+const (
+
+	// ConstructorCalledEvent is traced after a constructor or injected function is called
+	//
+	ConstructorCalledEvent EventKind = "constructor called"
+
+	// SingletonFoundEvent is traced when a singleton is taken from a scope instead of
+	// being constructed
+	//
+	SingletonFoundEvent EventKind = "singleton found"
+
+	// FieldDecoratedEvent is traced when a struct decoration tag sets a field
+	//
+	FieldDecoratedEvent EventKind = "field decorated"
+
+	// FieldSkippedEvent is traced when a struct decoration tag leaves a field alone
+	//
+	FieldSkippedEvent EventKind = "field skipped"
+
+	// ConfigLookedUpEvent is traced when a configuration key is looked up for AutoConfig
+	//
+	ConfigLookedUpEvent EventKind = "config looked up"
+)
+
+// Event describes something that happened while resolving. Only the fields
+// that apply to its kind are set
+//
+type Event struct {
+	Kind EventKind
+
+	// Type is the constructed type, or the struct holding a field
+	//
+	Type reflect.Type
+
+	// Name the constructor of Type was registered with
+	//
+	Name string
+
+	// Constructor is the name of the called constructor
+	//
+	Constructor string
+
+	// Field is the name of a decorated or skipped field and Tag
+	// the struct decoration tag that decorated or skipped it
+	//
+	Field string
+	Tag   reflect.Type
+
+	// Reason tells why a field was skipped
+	//
+	Reason string
+
+	// Key is the configuration key that was looked up and Configurator the
+	// type of configurator that answered. Configurator is nil when none did
+	//
+	Key          string
+	Configurator reflect.Type
+
+	// Err is the error returned by a constructor
+	//
+	Err error
+}
+
+// Tracer receives events while a scope resolves objects. Tracers should be safe
+// to use from multiple go routines
+//
+type Tracer interface {
+	Trace(event Event)
+}
+
+// tracingScope is implemented by scopes that can be traced, struct decoration
+// tags use it to report what they did
+//
+type tracingScope interface {
+	tracing() Tracer
+}
+
+// SetTracer sets the tracer of a scope. Scopes created by this scope use this
+// tracer as well, unless they have a tracer of their own. Use nil to stop tracing
+//
+func (scope *scope) SetTracer(tracer Tracer) {
+	scope.lock.Lock()
+	defer scope.lock.Unlock()
+
+	scope.tracer = tracer
+	scope.forgetLookups()
+}
+
+func (scope *scope) tracing() Tracer {
+	return scope.settings().tracer
+}
+
+// trace sends an event to the tracer of a scope. Events are only created when
+// the scope is traced
+//
+func (scope *scope) trace(event func() Event) {
+	if tracer := scope.tracing(); tracer != nil {
+		tracer.Trace(event())
+	}
+}
+
+// tracerOf returns the tracer of given scope, nil when it's not traced
+//
+func tracerOf(wire Scope) Tracer {
+	if tracing, ok := wire.(tracingScope); ok {
+		return tracing.tracing()
+	}
+	return nil
+}
+
+type slogTracer struct {
+	logger *slog.Logger
+}
+
+// NewSlogTracer returns a tracer that logs all events at debug level
+//
+func NewSlogTracer(logger *slog.Logger) Tracer {
+	return &slogTracer{logger: logger}
+}
+
+func (slogTracer *slogTracer) Trace(event Event) {
+	attributes := make([]slog.Attr, 0, 4)
+
+	addType := func(key string, objType reflect.Type) {
+		if objType != nil {
+			attributes = append(attributes, slog.String(key, fmt.Sprint(objType)))
+		}
+	}
+	addString := func(key string, value string) {
+		if value != "" {
+			attributes = append(attributes, slog.String(key, value))
+		}
+	}
+
+	addType("type", event.Type)
+	addString("name", event.Name)
+	addString("constructor", event.Constructor)
+	addString("field", event.Field)
+	addType("tag", event.Tag)
+	addString("reason", event.Reason)
+	addString("key", event.Key)
+	addType("configurator", event.Configurator)
+	if event.Err != nil {
+		attributes = append(attributes, slog.Any("error", event.Err))
+	}
+
+	slogTracer.logger.LogAttrs(context.Background(), slog.LevelDebug, "wired: "+string(event.Kind), attributes...)
+}
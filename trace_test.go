@@ -0,0 +1,96 @@
+package wired_test
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+type recordingTracer struct {
+	lock   sync.Mutex
+	events []wired.Event
+}
+
+func (recordingTracer *recordingTracer) Trace(event wired.Event) {
+	recordingTracer.lock.Lock()
+	defer recordingTracer.lock.Unlock()
+
+	recordingTracer.events = append(recordingTracer.events, event)
+}
+
+func (recordingTracer *recordingTracer) find(kind wired.EventKind, field string) *wired.Event {
+	for _, event := range recordingTracer.events {
+		if event.Kind == kind && event.Field == field {
+			return &event
+		}
+	}
+	return nil
+}
+
+type tracedSingleton struct {
+	wired.Singleton
+}
+
+type tracedStruct struct {
+	wired.AutoWire
+	wired.AutoConfig
+
+	Singleton *tracedSingleton
+	Unknown   *unknownStruct
+	Pepper    string `autoconfig:"${pepper}"`
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+
+	wired.Go(func(scope wired.Scope) {
+		scope.SetTracer(tracer)
+		scope.Register(newTestConfig)
+		scope.Register(func() *tracedSingleton { return &tracedSingleton{} })
+		scope.Register(func() *tracedStruct { return &tracedStruct{} })
+
+		scope.Go(func(inner wired.Scope) {
+			inner.Inject(func(traced *tracedStruct, singleton *tracedSingleton) {})
+		})
+	})
+
+	if event := tracer.find(wired.ConstructorCalledEvent, ""); event == nil || event.Constructor == "" {
+		t.Error("expected constructor calls to be traced, not", event)
+	}
+
+	if event := tracer.find(wired.SingletonFoundEvent, ""); event == nil || event.Type != reflect.TypeOf((*tracedSingleton)(nil)) {
+		t.Error("expected singleton to be found, not", event)
+	}
+
+	if event := tracer.find(wired.FieldDecoratedEvent, "Singleton"); event == nil || event.Tag != reflect.TypeOf(wired.AutoWire{}) {
+		t.Error("expected singleton field to be auto-wired, not", event)
+	}
+
+	if event := tracer.find(wired.FieldSkippedEvent, "Unknown"); event == nil || !strings.Contains(event.Reason, "do not know how to construct") {
+		t.Error("expected unknown field to be skipped, not", event)
+	}
+
+	if event := tracer.find(wired.ConfigLookedUpEvent, ""); event == nil || event.Key != "pepper" || event.Configurator == nil {
+		t.Error("expected config key to be looked up, not", event)
+	}
+}
+
+func TestSlogTracer(t *testing.T) {
+	var logged bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	wired.Go(func(scope wired.Scope) {
+		scope.SetTracer(wired.NewSlogTracer(logger))
+		scope.Register(newEmptyStruct)
+		scope.Inject(func(empty *emptyStruct) {})
+	})
+
+	if !strings.Contains(logged.String(), `msg="wired: constructor called" type=*wired_test.emptyStruct`) {
+		t.Error("expected constructor call to be logged, not", logged.String())
+	}
+}
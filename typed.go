@@ -0,0 +1,80 @@
+package wired
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypeOf returns the reflection type of T, also when T is an interface
+//
+func TypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get constructs an object of type T within given scope. It returns a
+// *ResolutionError when T is unknown or can not be constructed
+//
+func Get[T any](scope Scope) (T, error) {
+	return GetNamed[T](scope, "")
+}
+
+// GetNamed constructs an object of type T using the constructor registered
+// with given name
+//
+func GetNamed[T any](scope Scope, name string) (T, error) {
+	var result T
+
+	constructed, err := scope.TryConstructNamed(TypeOf[T](), name)
+	if err != nil || constructed == nil {
+		return result, err
+	}
+	return constructed.(T), nil
+}
+
+// MustGet constructs an object of type T within given scope and panics
+// when it can not be constructed
+//
+func MustGet[T any](scope Scope) T {
+	result, err := Get[T](scope)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Provide registers a constructor of T. When T is an interface, the constructor
+// may construct any type that implements T. Provide panics when the constructor
+// does not construct T
+//
+func Provide[T any](scope Scope, constructor interface{}) {
+	provide[T](scope, constructor)
+}
+
+// ProvideSingleton registers a constructor of T like Provide does, and
+// constructs only one object per scope, just like types that embed the
+// Singleton tag
+//
+func ProvideSingleton[T any](scope Scope, constructor interface{}) {
+	provide[T](scope, &tagged{constructor: constructor, tag: &singleton{}})
+}
+
+// ProvideWithLifetime registers a constructor of T like Provide does, and
+// constructs only one object per scope selected by given lifetime
+//
+func ProvideWithLifetime[T any](scope Scope, lifetime Lifetime, constructor interface{}) {
+	provide[T](scope, &tagged{constructor: constructor, tag: &lifetimeTag{lifetime: lifetime}})
+}
+
+func provide[T any](scope Scope, constructor interface{}) {
+	objType := TypeOf[T]()
+
+	outType, constructs := constructedType(ensureConstructorIsAFunction(constructor))
+	switch {
+	case constructs && outType == objType:
+		scope.Register(constructor)
+	case constructs && objType.Kind() == reflect.Interface && outType.Implements(objType):
+		scope.RegisterAs(constructor, objType)
+	default:
+		panic(fmt.Sprintf("constructor does not construct %v", objType))
+	}
+}
@@ -0,0 +1,60 @@
+package wired_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/okke/wired"
+	"github.com/okke/wired/internal"
+)
+
+func TestGet(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		wired.Provide[*emptyStruct](scope, newEmptyStruct)
+
+		empty, err := wired.Get[*emptyStruct](scope)
+		if err != nil || empty == nil {
+			t.Error("expected an empty struct, not", empty, err)
+		}
+
+		if _, err := wired.Get[*unknownStruct](scope); err == nil {
+			t.Error("expected an error for an unknown type")
+		}
+
+		var resolutionErr *wired.ResolutionError
+		if _, err := wired.GetNamed[*emptyStruct](scope, "unknown"); !errors.As(err, &resolutionErr) {
+			t.Error("expected a resolution error for an unknown name, not", err)
+		}
+
+		if wired.MustGet[*emptyStruct](scope) == nil {
+			t.Error("expected an empty struct")
+		}
+	})
+}
+
+func TestProvideInterface(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		wired.ProvideSingleton[reader](scope, newMemoryStore)
+
+		first := wired.MustGet[reader](scope)
+		second := wired.MustGet[reader](scope)
+
+		if first.Read() != "memory" {
+			t.Error("expected a memory store, not", first.Read())
+		}
+		if first != second {
+			t.Error("expected provided singleton to be constructed once")
+		}
+		if first != wired.MustGet[*memoryStore](scope) {
+			t.Error("expected interface and implementation to share the singleton")
+		}
+	})
+}
+
+func TestProvideShouldPanicOnWrongType(t *testing.T) {
+	defer internal.ShouldPanic(t)()
+
+	wired.Go(func(scope wired.Scope) {
+		wired.Provide[writer](scope, newMemoryStore)
+	})
+}
@@ -0,0 +1,181 @@
+package wired
+
+import (
+	"reflect"
+	"sort"
+)
+
+// fieldValidator can be implemented by struct decoration tags to check
+// upfront whether they are able to decorate a field
+//
+type fieldValidator interface {
+	validateField(scope *scope, structType reflect.Type, fieldType reflect.StructField) error
+}
+
+// Validate checks all constructors known to a scope and its parents
+//
+func (scope *scope) Validate() error {
+
+	problems := make(Errors, 0)
+	reported := make(map[string]bool)
+
+	report := func(err error) {
+		if !reported[err.Error()] {
+			reported[err.Error()] = true
+			problems = append(problems, err)
+		}
+	}
+
+	for walk := scope; walk != nil; walk = walk.parent {
+		for _, registration := range walk.sortedConstructors() {
+			scope.validateConstructor(walk, registration.constructor, report)
+		}
+	}
+	scope.validateDecorators(report)
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// registration is a constructor together with the binding it's registered for
+//
+type registration struct {
+	binding
+	constructor interface{}
+}
+
+// sortedConstructors returns all constructors of a scope ordered by the name
+// of the type they construct and the name they're registered with
+//
+func (scope *scope) sortedConstructors() []registration {
+	scope.lock.RLock()
+	defer scope.lock.RUnlock()
+
+	bindings := make([]binding, 0, len(scope.constructorMapping)+len(scope.namedMapping))
+	for objType := range scope.constructorMapping {
+		bindings = append(bindings, binding{objType: objType})
+	}
+	for key := range scope.namedMapping {
+		bindings = append(bindings, key)
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].objType != bindings[j].objType {
+			return bindings[i].objType.String() < bindings[j].objType.String()
+		}
+		return bindings[i].name < bindings[j].name
+	})
+
+	registrations := make([]registration, len(bindings))
+	for i, key := range bindings {
+		constructor, _ := scope.lookupConstructor(key.objType, key.name)
+		registrations[i] = registration{binding: key, constructor: constructor}
+	}
+	return registrations
+}
+
+// validateConstructor checks the arguments of a constructor registered in given
+// scope can be resolved
+//
+func (scope *scope) validateConstructor(registrar *scope, constructor interface{}, report func(error)) {
+
+	if aggregator, ok := constructor.(*aggregator); ok {
+		for _, element := range scope.elementsOf(aggregator.objType) {
+			scope.validateConstructor(element.registrar, element.constructor, report)
+		}
+		return
+	}
+
+	if alias, ok := constructor.(*alias); ok {
+		if !scope.canResolve(alias.target, alias.name) {
+			report(&ResolutionError{Type: alias.target, Name: alias.name, Path: []reflect.Type{alias.objType, alias.target}})
+		}
+		return
+	}
+
+	constructorType, err := functionType(constructor)
+	if err != nil {
+		report(&ResolutionError{Type: reflect.TypeOf(constructor), Err: err})
+		return
+	}
+
+	outType, constructs := constructedType(constructorType)
+
+	// owned objects are constructed using the constructors of their owner
+	//
+	resolving := scope
+	if isOwned(constructor) {
+		resolving = scope.owner(registrar)
+	}
+
+	for walk := 0; walk < constructorType.NumIn(); walk++ {
+		argType := constructorType.In(walk)
+		if !resolving.canResolve(argType, "") {
+			report(&ResolutionError{
+				Type:            argType,
+				Path:            []reflect.Type{outType, argType},
+				Constructor:     function(constructor),
+				constructorName: constructorName(constructor)})
+		}
+	}
+
+	if constructs {
+		resolving.validateFields(outType, report)
+	}
+}
+
+// validateDecorators checks the arguments injected into decorators known to a
+// scope and its parents can be resolved
+//
+func (scope *scope) validateDecorators(report func(error)) {
+	decorators := scope.settings().decorators
+
+	decoratedTypes := make([]reflect.Type, 0, len(decorators))
+	for decoratedType := range decorators {
+		decoratedTypes = append(decoratedTypes, decoratedType)
+	}
+	sort.Slice(decoratedTypes, func(i, j int) bool {
+		return decoratedTypes[i].String() < decoratedTypes[j].String()
+	})
+
+	for _, decoratedType := range decoratedTypes {
+		for _, decorator := range decorators[decoratedType] {
+			decoratorType := reflect.TypeOf(decorator)
+
+			// the first argument is the decorated object itself
+			//
+			for walk := 1; walk < decoratorType.NumIn(); walk++ {
+				argType := decoratorType.In(walk)
+				if !scope.canResolve(argType, "") {
+					report(&ResolutionError{
+						Type:            argType,
+						Path:            []reflect.Type{decoratedType, argType},
+						Constructor:     function(decorator),
+						constructorName: constructorName(decorator)})
+				}
+			}
+		}
+	}
+}
+
+func (scope *scope) validateFields(objType reflect.Type, report func(error)) {
+
+	if objType.Kind() == reflect.Ptr {
+		objType = objType.Elem()
+	}
+
+	if objType.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, decorator := range FindStructDecorationTags(objType) {
+		if validator, ok := decorator.(fieldValidator); ok {
+			for walk := 0; walk < objType.NumField(); walk++ {
+				if err := validator.validateField(scope, objType, objType.Field(walk)); err != nil {
+					report(err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package wired_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/okke/wired"
+)
+
+var validationCalls = 0
+
+type validatedRoom struct {
+	wired.AutoWire
+
+	Table *validatedTable
+	Chair *validatedChair
+	Name  string
+}
+
+type validatedTable struct {
+}
+
+type validatedChair struct {
+}
+
+type validatedLamp struct {
+}
+
+func newValidatedRoom() *validatedRoom {
+	validationCalls = validationCalls + 1
+	return &validatedRoom{}
+}
+
+func newValidatedTable() *validatedTable {
+	validationCalls = validationCalls + 1
+	return &validatedTable{}
+}
+
+func newValidatedLampNeedingChair(chair *validatedChair) *validatedLamp {
+	validationCalls = validationCalls + 1
+	return &validatedLamp{}
+}
+
+func newValidatedTableNeedingLamp(lamp *validatedLamp) *validatedTable {
+	validationCalls = validationCalls + 1
+	return &validatedTable{}
+}
+
+func TestValidateWithoutProblems(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newValidatedTable)
+		scope.Register(func() *validatedChair { return &validatedChair{} })
+		scope.Register(newValidatedRoom)
+
+		if err := scope.Validate(); err != nil {
+			t.Error("expected no problems, not", err)
+		}
+	})
+}
+
+func TestValidateShouldReportAllProblems(t *testing.T) {
+	validationCalls = 0
+
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newValidatedTable)
+		scope.Register(newValidatedRoom)
+
+		scope.Go(func(inner wired.Scope) {
+			inner.Register(newValidatedLampNeedingChair)
+			inner.Register(newValidatedTableNeedingLamp)
+
+			err := inner.Validate()
+
+			var problems wired.Errors
+			if !errors.As(err, &problems) {
+				t.Fatal("expected multiple problems, not", err)
+			}
+
+			// validatedRoom misses a chair field, and a lamp misses a chair argument
+			//
+			if len(problems) != 2 {
+				t.Fatal("expected 2 problems, not", len(problems), problems)
+			}
+
+			var fieldErr *wired.FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != "Chair" {
+				t.Error("expected the chair field to be reported, not", fieldErr)
+			}
+
+			var resolutionErr *wired.ResolutionError
+			if !errors.As(problems[0], &resolutionErr) || resolutionErr.Type != reflect.TypeOf((*validatedChair)(nil)) {
+				t.Error("expected missing chair to be reported, not", problems[0])
+			}
+		})
+
+		// parent does not know about lamps
+		//
+		var fieldErr *wired.FieldError
+		if err := scope.Validate(); !errors.As(err, &fieldErr) || len(err.(wired.Errors)) != 1 {
+			t.Error("expected only the missing chair field to be reported, not", err)
+		}
+	})
+
+	if validationCalls != 0 {
+		t.Error("validation should not call constructors, but it called", validationCalls)
+	}
+}
+
+type validatedReplica struct {
+}
+
+type usesValidatedReplica struct {
+	wired.AutoWire
+
+	Replica *validatedReplica `autowire:"replica"`
+}
+
+func TestValidateNamedField(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *validatedReplica { return &validatedReplica{} })
+		scope.Register(func() *usesValidatedReplica { return &usesValidatedReplica{} })
+
+		var resolutionErr *wired.ResolutionError
+		if err := scope.Validate(); !errors.As(err, &resolutionErr) || resolutionErr.Name != "replica" {
+			t.Error("expected missing replica to be reported, not", err)
+		}
+
+		scope.RegisterNamed("replica", func() *validatedReplica { return &validatedReplica{} })
+
+		if err := scope.Validate(); err != nil {
+			t.Error("expected no problems, not", err)
+		}
+	})
+}
+
+type validatedOptional struct {
+	wired.AutoWire
+
+	Optional *validatedReplica `autowire:"replica,optional"`
+	Skipped  *validatedReplica `autowire:"-"`
+}
+
+func TestValidateShouldIgnoreOptionalFields(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(func() *validatedOptional { return &validatedOptional{} })
+
+		if err := scope.Validate(); err != nil {
+			t.Error("expected optional fields not to be reported, not", err)
+		}
+	})
+}
+
+func TestValidateSingletonWithBindingsOfItsOwner(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newSingletonWithDependency)
+
+		scope.Go(func(inner wired.Scope) {
+			inner.Register(func() *singletonDependency { return &singletonDependency{} })
+
+			var resolutionErr *wired.ResolutionError
+			if err := inner.Validate(); !errors.As(err, &resolutionErr) || resolutionErr.Type != reflect.TypeOf(&singletonDependency{}) {
+				t.Error("expected dependency of singleton owned by parent to be reported, not", err)
+			}
+
+			if _, err := wired.Get[*singletonWithDependency](inner); err == nil {
+				t.Error("expected singleton owned by parent not to be constructed")
+			}
+
+			inner.RebuildSingletons()
+			if err := inner.Validate(); err != nil {
+				t.Error("expected no problems when singletons are rebuilt, not", err)
+			}
+		})
+	})
+}
+
+func TestValidateShouldReportUnknownArgumentsOfDecorators(t *testing.T) {
+	wired.Go(func(scope wired.Scope) {
+		scope.Register(newValidatedTable)
+		scope.Decorate(func(table *validatedTable, lamp *validatedLamp) *validatedTable { return table })
+
+		var resolutionErr *wired.ResolutionError
+		if err := scope.Validate(); !errors.As(err, &resolutionErr) || resolutionErr.Type != reflect.TypeOf((*validatedLamp)(nil)) {
+			t.Error("expected an unknown lamp needed by a decorator, not", err)
+		}
+
+		scope.Register(func() *validatedLamp { return &validatedLamp{} })
+		if err := scope.Validate(); err != nil {
+			t.Error("expected decorator arguments to be known, not", err)
+		}
+	})
+}